@@ -0,0 +1,160 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+
+	"github.com/jessevdk/go-flags"
+
+	"github.com/avito-tech/go-mutesting/internal/accepted"
+	"github.com/avito-tech/go-mutesting/internal/models"
+	"github.com/avito-tech/go-mutesting/internal/reportmaker"
+)
+
+func acceptCmd(args []string) int {
+	var opts = &models.AcceptOptions{}
+
+	p := flags.NewNamedParser("go-mutesting accept", flags.None)
+
+	p.ShortDescription = "Record the surviving mutations of a report in an accepted mutations file (see --accepted), so they do not count against the score of later runs"
+
+	if _, err := p.AddGroup("accept", "accept arguments", opts); err != nil {
+		return exitError(err.Error())
+	}
+
+	_, err := p.ParseArgs(args)
+	if opts.General.Help {
+		p.WriteHelp(os.Stdout)
+
+		return returnHelp
+	}
+	if err != nil {
+		return exitError(err.Error())
+	}
+
+	if opts.Acceptance.Expires != "" {
+		if _, err := time.Parse(accepted.DateLayout, opts.Acceptance.Expires); err != nil {
+			return exitError("--expires %q is not a YYYY-MM-DD date", opts.Acceptance.Expires)
+		}
+	}
+
+	for _, pattern := range opts.Filter.File {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return exitError("--file %q is not a valid glob pattern: %v", pattern, err)
+		}
+	}
+
+	var function *regexp.Regexp
+	if opts.Filter.Function != "" {
+		function, err = regexp.Compile(opts.Filter.Function)
+		if err != nil {
+			return exitError("--function %q is not a valid regex: %v", opts.Filter.Function, err)
+		}
+	}
+
+	report, err := reportmaker.ReadJSONReport(opts.Files.Report)
+	if err != nil {
+		return exitError("Could not read report %q: %v", opts.Files.Report, err)
+	}
+
+	existing := make(map[string]struct{})
+	if _, err := os.Stat(opts.Files.Accepted); err == nil {
+		file, err := accepted.Load(opts.Files.Accepted)
+		if err != nil {
+			return exitError(err.Error())
+		}
+
+		for _, entry := range file.Mutants {
+			existing[entry.ID] = struct{}{}
+		}
+	}
+
+	var entries []models.Acceptance
+	already := 0
+	withoutID := 0
+
+	// Mutations which no test covers survive as well
+	surviving := append(append([]models.Mutant(nil), report.Escaped...), report.NotCovered...)
+
+	for _, mutant := range surviving {
+		if !acceptFilter(opts, function, mutant) {
+			continue
+		}
+
+		// Reports of older versions do not identify mutants by ID
+		if mutant.ID == "" {
+			withoutID++
+
+			continue
+		}
+		if _, ok := existing[mutant.ID]; ok {
+			already++
+
+			continue
+		}
+		existing[mutant.ID] = struct{}{}
+
+		entries = append(entries, models.Acceptance{
+			ID:      mutant.ID,
+			Mutator: mutant.Mutator.MutatorName,
+			File:    fmt.Sprintf("%s:%d", mutant.Mutator.OriginalFilePath, mutant.Mutator.OriginalStartLine),
+			Reason:  opts.Acceptance.Reason,
+			Owner:   opts.Acceptance.Owner,
+			Expires: opts.Acceptance.Expires,
+		})
+	}
+
+	if withoutID > 0 {
+		_, _ = fmt.Fprintf(os.Stderr, "WARNING %d surviving mutations have no ID and cannot be accepted, the report was written by an older version\n", withoutID)
+	}
+
+	if len(entries) > 0 {
+		if err := accepted.Append(opts.Files.Accepted, entries); err != nil {
+			return exitError("Could not write accepted mutations file %q: %v", opts.Files.Accepted, err)
+		}
+	}
+
+	fmt.Printf("Accepted %d surviving mutations into %q (%d were already accepted)\n", len(entries), opts.Files.Accepted, already)
+
+	return returnOk
+}
+
+// acceptFilter returns if the surviving mutant matches all filters of the accept command.
+func acceptFilter(opts *models.AcceptOptions, function *regexp.Regexp, mutant models.Mutant) bool {
+	if len(opts.Filter.File) > 0 {
+		matched := false
+		for _, pattern := range opts.Filter.File {
+			if ok, _ := filepath.Match(pattern, mutant.Mutator.OriginalFilePath); ok {
+				matched = true
+
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+
+	if function != nil && !function.MatchString(mutant.Function) {
+		return false
+	}
+
+	if len(opts.Filter.Mutator) > 0 {
+		matched := false
+		for _, pattern := range opts.Filter.Mutator {
+			if matchMutator(pattern, mutant.Mutator.MutatorName) {
+				matched = true
+
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+
+	return true
+}
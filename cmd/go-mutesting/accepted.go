@@ -0,0 +1,41 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/avito-tech/go-mutesting/internal/accepted"
+	"github.com/avito-tech/go-mutesting/internal/models"
+)
+
+// loadAccepted returns the entries of the accepted mutations file which did not expire by the IDs of their mutations.
+// Expired entries are only warned about, their mutations count against the score again.
+func loadAccepted(path string) (map[string]models.Acceptance, error) {
+	file, err := accepted.Load(path)
+	if err != nil {
+		return nil, err
+	}
+
+	active, expired := file.Active(time.Now())
+	for _, entry := range expired {
+		msg := fmt.Sprintf("WARNING the acceptance of mutation %s", entry.ID)
+		if entry.File != "" {
+			msg += fmt.Sprintf(" at %s", entry.File)
+		}
+		if entry.Owner != "" {
+			msg += fmt.Sprintf(" (owner %s)", entry.Owner)
+		}
+		fmt.Printf("%s expired on %s, it counts against the score again\n", msg, entry.Expires)
+	}
+
+	return active, nil
+}
+
+// markAccepted sets the acceptance of the mutations which are listed in the accepted mutations file.
+func markAccepted(jobs []mutantJob, entries map[string]models.Acceptance) {
+	for i := range jobs {
+		if entry, ok := entries[jobs[i].id]; ok {
+			jobs[i].acceptance = &entry
+		}
+	}
+}
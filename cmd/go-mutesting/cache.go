@@ -0,0 +1,237 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/avito-tech/go-mutesting/internal/cache"
+	"github.com/avito-tech/go-mutesting/internal/console"
+	"github.com/avito-tech/go-mutesting/internal/models"
+)
+
+// goListPackage is the part of the output of go list -json which tells the files a package is built from
+type goListPackage struct {
+	Dir          string
+	ImportPath   string
+	Standard     bool
+	DepOnly      bool
+	GoFiles      []string
+	CgoFiles     []string
+	CFiles       []string
+	HFiles       []string
+	SFiles       []string
+	EmbedFiles   []string
+	TestGoFiles  []string
+	XTestGoFiles []string
+}
+
+// cacheKeys assigns every mutation the key of its result in the cache.
+// The key covers the mutated source, the sources, tests and dependencies of its package, and the options which change results.
+func cacheKeys(opts *models.Options, jobs []mutantJob) {
+	goVersion, err := exec.Command("go", "env", "GOVERSION").Output()
+	if err != nil {
+		fmt.Printf("Could not find the version of the go tool, results are not cached: %v\n", err)
+
+		return
+	}
+
+	dependents := make(map[string][]string)
+	for _, job := range jobs {
+		dependents[job.pkg.Path()] = job.dependents
+	}
+
+	fingerprints := make(map[string]string)
+	for _, pkgName := range sortedPackages(jobs) {
+		fingerprint, err := packageFingerprint(pkgName, opts.Test.Recursive, dependents[pkgName])
+		if err != nil {
+			fmt.Printf("Could not fingerprint %q, its results are not cached: %v\n", pkgName, err)
+
+			continue
+		}
+
+		fingerprints[pkgName] = fingerprint
+	}
+
+	for i := range jobs {
+		job := &jobs[i]
+
+		fingerprint, ok := fingerprints[job.pkg.Path()]
+		if !ok || job.notCovered {
+			continue
+		}
+
+		mutation, err := os.ReadFile(job.mutationFile)
+		if err != nil {
+			continue
+		}
+
+		// The original file is part of the fingerprint, but the mutation replaces it
+		job.cacheKey = cache.Key(
+			strings.TrimSpace(string(goVersion)),
+			fingerprint,
+			filepath.Base(job.originalFile),
+			hashBytes(mutation),
+			opts.Exec.Exec,
+			strconv.FormatBool(opts.Test.Recursive),
+			strconv.FormatBool(opts.Test.KillMatrix),
+			fmt.Sprintf("%+v", opts.Config.Stages),
+			// Fewer tests may let the mutation escape, see --select-tests
+			fmt.Sprintf("%+v", job.tests),
+		)
+
+		console.Debug(opts, "Cache key of %q is %s", job.mutationFile, job.cacheKey)
+	}
+}
+
+// openCache opens the local and the shared result cache, of which at least one has to be configured.
+func openCache(opts *models.Options) (cache.Store, error) {
+	var stores []cache.Store
+
+	if opts.Cache.Dir != "" {
+		local, err := cache.Open(opts.Cache.Dir)
+		if err != nil {
+			return nil, err
+		}
+
+		stores = append(stores, local)
+	}
+	if opts.Cache.URL != "" {
+		remote, err := cache.NewHTTP(opts.Cache.URL)
+		if err != nil {
+			return nil, err
+		}
+
+		stores = append(stores, remote)
+	}
+
+	return cache.Chain(stores...), nil
+}
+
+// lookupCache marks the mutations whose result is found in the cache.
+func lookupCache(opts *models.Options, results cache.Store, jobs []mutantJob) {
+	count := 0
+
+	for i := range jobs {
+		job := &jobs[i]
+		if job.cacheKey == "" {
+			continue
+		}
+
+		entry, ok, err := results.Get(job.cacheKey)
+		if err != nil {
+			fmt.Printf("Could not read the cached result of %q, the failing cache is not used anymore: %v\n", job.mutationFile, err)
+		}
+		if ok {
+			job.cached = &entry
+			count++
+		}
+	}
+
+	console.Verbose(opts, "Found %d of %d results in the cache", count, len(jobs))
+}
+
+// packageFingerprint returns a hash of all files the tests of the given package and of its dependent packages depend on.
+// Packages of the standard library are covered by the version of the go tool.
+func packageFingerprint(pkgName string, recursive bool, dependents []string) (string, error) {
+	pattern := pkgName
+	if recursive {
+		pattern += "/..."
+	}
+
+	var stderr bytes.Buffer
+	cmd := exec.Command("go", append([]string{"list", "-deps", "-test", "-json", pattern}, dependents...)...)
+	cmd.Stderr = &stderr
+
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("%v: %s", err, stderr.String())
+	}
+
+	// files maps the name of every file in the hash to its path
+	files := make(map[string]string)
+
+	decoder := json.NewDecoder(bytes.NewReader(output))
+	for {
+		var pkg goListPackage
+		err := decoder.Decode(&pkg)
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return "", err
+		}
+
+		// Test variants are listed as "path [path.test]", and the generated test main is not a source
+		importPath, _, _ := strings.Cut(pkg.ImportPath, " ")
+		if pkg.Standard || strings.HasSuffix(importPath, ".test") {
+			continue
+		}
+
+		sources := [][]string{pkg.GoFiles, pkg.CgoFiles, pkg.CFiles, pkg.HFiles, pkg.SFiles, pkg.EmbedFiles}
+		if !pkg.DepOnly {
+			sources = append(sources, pkg.TestGoFiles, pkg.XTestGoFiles)
+
+			// Tests read their fixtures from testdata
+			err := filepath.WalkDir(filepath.Join(pkg.Dir, "testdata"), func(file string, entry fs.DirEntry, err error) error {
+				if errors.Is(err, fs.ErrNotExist) {
+					return nil
+				} else if err != nil || entry.IsDir() {
+					return err
+				}
+
+				rel, err := filepath.Rel(pkg.Dir, file)
+				if err != nil {
+					return err
+				}
+				files[path.Join(importPath, filepath.ToSlash(rel))] = file
+
+				return nil
+			})
+			if err != nil {
+				return "", err
+			}
+		}
+
+		for _, names := range sources {
+			for _, name := range names {
+				files[path.Join(importPath, name)] = filepath.Join(pkg.Dir, name)
+			}
+		}
+	}
+
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	h := sha256.New()
+	for _, name := range names {
+		content, err := os.ReadFile(files[name])
+		if err != nil {
+			return "", err
+		}
+
+		_, _ = fmt.Fprintf(h, "%s %s\n", name, hashBytes(content))
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func hashBytes(content []byte) string {
+	sum := sha256.Sum256(content)
+
+	return hex.EncodeToString(sum[:])
+}
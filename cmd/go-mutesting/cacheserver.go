@@ -0,0 +1,61 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/jessevdk/go-flags"
+
+	"github.com/avito-tech/go-mutesting/internal/cache"
+	"github.com/avito-tech/go-mutesting/internal/models"
+)
+
+func cacheServerCmd(args []string) int {
+	var opts = &models.CacheServerOptions{}
+
+	p := flags.NewNamedParser("go-mutesting cache-server", flags.None)
+
+	p.ShortDescription = "Serve a result cache which is shared by runs on other machines (see --cache-url)"
+
+	if _, err := p.AddGroup("cache-server", "cache-server arguments", opts); err != nil {
+		return exitError(err.Error())
+	}
+
+	_, err := p.ParseArgs(args)
+	if opts.General.Help {
+		p.WriteHelp(os.Stdout)
+
+		return returnHelp
+	}
+	if err != nil {
+		return exitError(err.Error())
+	}
+
+	store, err := cache.Open(opts.Server.Dir)
+	if err != nil {
+		return exitError(err.Error())
+	}
+
+	handler := cache.Handler(store)
+	if opts.General.Verbose {
+		next := handler
+		handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			log.Printf("%s %s", r.Method, r.URL.Path)
+
+			next.ServeHTTP(w, r)
+		})
+	}
+
+	server := &http.Server{
+		Addr:              opts.Server.Listen,
+		Handler:           handler,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	fmt.Printf("Serve the result cache in %q on %s\n", opts.Server.Dir, opts.Server.Listen)
+
+	return exitError(server.ListenAndServe().Error())
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"path/filepath"
+
+	"github.com/avito-tech/go-mutesting/internal/parser"
+)
+
+// changedFiles returns the files which have changed lines.
+func changedFiles(files []string, changed map[string][]int64) []string {
+	var remaining []string
+	for _, file := range files {
+		fileAbs, err := filepath.Abs(file)
+		if err != nil {
+			continue
+		}
+
+		if _, ok := changed[fileAbs]; ok {
+			remaining = append(remaining, file)
+		}
+	}
+
+	return remaining
+}
+
+// changedLineJobs returns the mutations which change one of the given changed lines of their file.
+// Mutations of blocks are generated as long as any line of the block changed, so not all of them change a changed line themselves.
+func changedLineJobs(jobs []mutantJob, changed map[string][]int64) []mutantJob {
+	var remaining []mutantJob
+
+	for _, job := range jobs {
+		fileAbs, err := filepath.Abs(job.originalFile)
+		if err != nil {
+			continue
+		}
+
+		changedLines := make(map[int64]struct{})
+		for _, line := range changed[fileAbs] {
+			changedLines[line] = struct{}{}
+		}
+
+		for _, line := range parser.ChangedLines(mutationDiff(job.originalFile, job.mutationFile)) {
+			if _, ok := changedLines[line]; ok {
+				job.onChangedLines = true
+				remaining = append(remaining, job)
+
+				break
+			}
+		}
+	}
+
+	return remaining
+}
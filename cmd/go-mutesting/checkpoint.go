@@ -0,0 +1,28 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/avito-tech/go-mutesting/internal/models"
+)
+
+// resumeJobs adds the stored results of an interrupted run to the reports and returns the mutations which still have to be tested.
+func resumeJobs(opts *models.Options, jobs []mutantJob, resumed map[string]models.Mutant, report *models.Report, random *models.Report) []mutantJob {
+	var remaining []mutantJob
+
+	for _, job := range jobs {
+		mutant, ok := resumed[job.id]
+		if !ok {
+			remaining = append(remaining, job)
+
+			continue
+		}
+
+		report.Add(mutant)
+		random.Add(mutant)
+	}
+
+	fmt.Printf("Resume %d of %d mutations from checkpoint %q\n", len(jobs)-len(remaining), len(jobs), opts.Exec.Resume)
+
+	return remaining
+}
@@ -0,0 +1,238 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/avito-tech/go-mutesting/internal/console"
+	"github.com/avito-tech/go-mutesting/internal/importing"
+	"github.com/avito-tech/go-mutesting/internal/models"
+	"github.com/avito-tech/go-mutesting/internal/runner"
+	"github.com/zimmski/osutil"
+)
+
+// consumer module whose tests test the mutations of the library as well, see --consumer
+type consumer struct {
+	dir string
+	// modFile copy of the go.mod of the consumer which replaces the library by its local sources
+	modFile string
+	// packages of the consumer which import a mutated package directly or indirectly, by the path of the mutated package
+	packages map[string][]string
+	timeout  time.Duration
+}
+
+// prepareConsumers sets up the consumer modules of --consumer and tests them with the original code of the library.
+// The library is the module of the current directory, every consumer builds against its local sources through a replace directive.
+func prepareConsumers(opts *models.Options, tmpDir string, jobs []mutantJob, importPaths map[string]string) ([]*consumer, error) {
+	library, err := exec.Command("go", "list", "-m", "-f", "{{.Path}} {{.Dir}}").Output()
+	if err != nil {
+		return nil, fmt.Errorf("could not find the module of the library: %v", err)
+	}
+	libraryPath, libraryDir, _ := strings.Cut(strings.TrimSpace(string(library)), " ")
+
+	libraryPackages, err := importing.ListModule(".")
+	if err != nil {
+		return nil, fmt.Errorf("could not list the packages of the library: %v", err)
+	}
+
+	var consumers []*consumer
+
+	for i, dir := range opts.Test.Consumers {
+		dir, err := filepath.Abs(dir)
+		if err != nil {
+			return nil, err
+		}
+
+		c := &consumer{
+			dir:      dir,
+			modFile:  filepath.Join(tmpDir, fmt.Sprintf("consumer.%d.mod", i)),
+			packages: make(map[string][]string),
+		}
+
+		if err := osutil.CopyFile(filepath.Join(dir, "go.mod"), c.modFile); err != nil {
+			return nil, fmt.Errorf("could not copy the go.mod of consumer %q: %v", dir, err)
+		}
+		// The go tool reads the checksums of a modfile from the file next to it
+		sumFile := strings.TrimSuffix(c.modFile, ".mod") + ".sum"
+		if err := osutil.CopyFile(filepath.Join(dir, "go.sum"), sumFile); err != nil && !os.IsNotExist(err) {
+			return nil, fmt.Errorf("could not copy the go.sum of consumer %q: %v", dir, err)
+		}
+
+		replace := exec.Command("go", "mod", "edit", "-replace="+libraryPath+"="+libraryDir, c.modFile)
+		replace.Dir = dir
+		if out, err := replace.CombinedOutput(); err != nil {
+			return nil, fmt.Errorf("could not replace the library in the go.mod of consumer %q: %v: %s", dir, err, out)
+		}
+
+		consumerPackages, err := importing.ListModule(dir, "-mod=mod", "-modfile", c.modFile)
+		if err != nil {
+			return nil, fmt.Errorf("could not list the packages of consumer %q: %v", dir, err)
+		}
+		own := make(map[string]struct{}, len(consumerPackages))
+		for _, pkg := range consumerPackages {
+			own[pkg.ImportPath] = struct{}{}
+		}
+
+		// Consumers may import the mutated package through other packages of the library
+		graph := append(append([]importing.ModulePackage(nil), libraryPackages...), consumerPackages...)
+
+		var all []string
+		for _, pkgName := range sortedPackages(jobs) {
+			importPath, ok := importPaths[pkgName]
+			if !ok {
+				continue
+			}
+
+			for _, dependent := range importing.Dependents(graph, importPath, 0, 0) {
+				if _, ok := own[dependent]; ok {
+					c.packages[pkgName] = append(c.packages[pkgName], dependent)
+				}
+			}
+			for _, dependent := range c.packages[pkgName] {
+				if !slices.Contains(all, dependent) {
+					all = append(all, dependent)
+				}
+			}
+		}
+
+		if len(all) == 0 {
+			fmt.Printf("No package of consumer %q imports a mutated package, its tests are not run\n", dir)
+
+			continue
+		}
+
+		// The tests of the consumer have to pass on the original library, just like the baseline of a package
+		var duration time.Duration
+		for _, stage := range consumerStages(opts) {
+			var out bytes.Buffer
+			start := time.Now()
+			result, err := consumerTest(opts, c, stage, "", all, time.Duration(opts.Exec.Timeout)*time.Second, &out)
+			if err != nil {
+				return nil, fmt.Errorf("could not run the tests of consumer %q: %v", dir, err)
+			}
+			duration = max(duration, time.Since(start))
+
+			if result.Status != models.StatusEscaped {
+				_, _ = os.Stdout.Write(out.Bytes())
+				_, _ = os.Stdout.WriteString(result.Output)
+
+				if stage.Name != "" {
+					return nil, fmt.Errorf("the tests of consumer %q do not pass on the original code of the library in stage %s (%s)", dir, stage.Name, result.Status)
+				}
+
+				return nil, fmt.Errorf("the tests of consumer %q do not pass on the original code of the library (%s)", dir, result.Status)
+			}
+		}
+
+		if opts.Exec.Timeout > 0 {
+			c.timeout = time.Duration(opts.Exec.Timeout) * time.Second
+		} else {
+			c.timeout = runner.Timeout(duration, opts.Exec.TimeoutFactor, minExecTimeout)
+		}
+
+		console.Verbose(opts, "Tests of %d packages of consumer %q pass on the original code in %s", len(all), dir, duration.Round(time.Millisecond))
+
+		consumers = append(consumers, c)
+	}
+
+	return consumers, nil
+}
+
+// consumerStages returns the test stages the tests of consumers are run with, a single stage without flags if there are none.
+// Stages with an exec command of their own only test the library.
+func consumerStages(opts *models.Options) []models.Stage {
+	if len(opts.Config.Stages) == 0 {
+		return []models.Stage{{}}
+	}
+
+	var stages []models.Stage
+	for _, stage := range opts.Config.Stages {
+		if stage.Exec == "" {
+			stages = append(stages, stage)
+		}
+	}
+
+	return stages
+}
+
+// mutateConsumers tests the mutation with the tests of one consumer after another, until a consumer does not let it survive.
+// Every test stage runs the tests of all consumers before the next stage starts.
+func mutateConsumers(opts *models.Options, out io.Writer, job mutantJob, result runner.TestResult, mutant *models.Mutant) runner.TestResult {
+	overlayFile := job.mutationFile + ".consumer.overlay.json"
+	if err := runner.WriteOverlay(overlayFile, job.originalFile, job.mutationFile); err != nil {
+		panic(err)
+	}
+
+	for _, stage := range consumerStages(opts) {
+		for _, c := range job.consumers {
+			packages := c.packages[job.pkg.Path()]
+			if len(packages) == 0 {
+				continue
+			}
+
+			timeout := c.timeout
+			if stage.Timeout > 0 {
+				timeout = stage.Timeout
+			}
+
+			console.Fverbose(out, opts, "Test mutation with the tests of consumer %q", c.dir)
+
+			consumerResult, err := consumerTest(opts, c, stage, overlayFile, packages, timeout, out)
+			if err != nil {
+				consumerResult = runner.TestResult{
+					Status: models.StatusError,
+					Output: fmt.Sprintf("could not run the tests of consumer %q: %v", c.dir, err),
+				}
+			}
+			if consumerResult.Status == models.StatusEscaped {
+				continue
+			}
+
+			mutant.Consumer = c.dir
+			if stage.Name != "" {
+				mutant.Stage = stage.Name
+			}
+
+			return consumerResult
+		}
+	}
+
+	return result
+}
+
+// consumerTest runs the tests of the given packages of the consumer against the library in the given test stage, with the given overlay if it is set.
+func consumerTest(
+	opts *models.Options,
+	c *consumer,
+	stage models.Stage,
+	overlayFile string,
+	packages []string,
+	timeout time.Duration,
+	out io.Writer,
+) (runner.TestResult, error) {
+	result, _, err := runner.GoTest{
+		Packages: packages,
+		Dir:      c.dir,
+		Overlay:  overlayFile,
+		ModFile:  c.modFile,
+		Flags:    stageFlags(stage),
+		FailFast: !opts.Test.KillMatrix,
+		Timeout:  timeout,
+	}.Run()
+	if err != nil {
+		return result, err
+	}
+
+	if opts.General.Debug {
+		_, _ = fmt.Fprintf(out, "%s\n", result.Output)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"fmt"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/avito-tech/go-mutesting/internal/console"
+	"github.com/avito-tech/go-mutesting/internal/coverage"
+	"github.com/avito-tech/go-mutesting/internal/models"
+	"github.com/avito-tech/go-mutesting/internal/parser"
+)
+
+// resolveImportPath adds the import path of the package with the given path to importPaths, as it is named in coverage profiles and test events.
+// Packages whose import path cannot be resolved are left out and added to unresolved, so they are only looked up once.
+func resolveImportPath(importPaths map[string]string, unresolved map[string]struct{}, pkgName string) {
+	if _, ok := importPaths[pkgName]; ok {
+		return
+	}
+	if _, ok := unresolved[pkgName]; ok {
+		return
+	}
+
+	importPath, err := exec.Command("go", "list", "-f", "{{.ImportPath}}", pkgName).Output()
+	if err != nil {
+		fmt.Printf("Could not find the import path of %q, its mutation IDs use its path and its coverage is unknown: %v\n", pkgName, err)
+		unresolved[pkgName] = struct{}{}
+
+		return
+	}
+
+	importPaths[pkgName] = strings.TrimSpace(string(importPath))
+}
+
+// coverageFile returns the name of the original file of the given mutation in coverage profiles.
+func coverageFile(job mutantJob, importPaths map[string]string) (string, bool) {
+	importPath, ok := importPaths[job.pkg.Path()]
+	if !ok {
+		return "", false
+	}
+
+	return path.Join(importPath, filepath.Base(job.originalFile)), true
+}
+
+// markNotCovered marks the mutations whose changed lines are only part of code which the tests never executed.
+func markNotCovered(opts *models.Options, jobs []mutantJob, profiles map[string]coverage.Profile, importPaths map[string]string) {
+	for i := range jobs {
+		job := &jobs[i]
+
+		profile, ok := profiles[job.pkg.Path()]
+		if !ok {
+			continue
+		}
+		file, ok := coverageFile(*job, importPaths)
+		if !ok {
+			continue
+		}
+
+		lines := parser.ChangedLines(mutationDiff(job.originalFile, job.mutationFile))
+		if profile.Uncovered(file, lines) {
+			job.notCovered = true
+
+			console.Debug(opts, "%q is not covered by any test", job.mutationFile)
+		}
+	}
+}
@@ -0,0 +1,289 @@
+package main
+
+import (
+	"bytes"
+	"crypto/md5"
+	"fmt"
+	"go/ast"
+	"go/printer"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/avito-tech/go-mutesting/internal/filter"
+	"github.com/avito-tech/go-mutesting/mutator"
+)
+
+// mutantIDs assigns stable IDs to mutations.
+// An ID is derived from the import path of the package, the name of the file, the enclosing function, the mutator, the replaced code and the index among mutations which are identical in all of these.
+// Unlike the checksum of the mutated file it does not change if code outside of the replaced code changes.
+type mutantIDs struct {
+	// importPaths resolved import paths of the mutated packages by their path, see resolveImportPath
+	importPaths map[string]string
+	seen        map[string]int
+	// byChecksum IDs of the saved mutations by the checksum of their file
+	byChecksum map[string]string
+}
+
+func newMutantIDs(importPaths map[string]string) *mutantIDs {
+	return &mutantIDs{
+		importPaths: importPaths,
+		seen:        make(map[string]int),
+		byChecksum:  make(map[string]string),
+	}
+}
+
+// importPath returns the import path of the package with the given path, or the path itself if it could not be resolved.
+func (ids *mutantIDs) importPath(pkgPath string) string {
+	if importPath, ok := ids.importPaths[pkgPath]; ok {
+		return importPath
+	}
+
+	return pkgPath
+}
+
+// mutator wraps the given mutator so that every mutation it generates takes an ID, the IDs of the mutations which are not skipped are appended to assigned in the order of the mutations.
+// Mutations of nodes which the filters skip take an ID as well, so the IDs do not depend on which filters are active.
+func (ids *mutantIDs) mutator(m mutatorItem, importPath string, file string, src ast.Node, filters []filter.NodeFilter, assigned *[]string) mutator.Mutator {
+	return func(pkg *types.Package, info *types.Info, node ast.Node) []mutator.Mutation {
+		mutations := m.Mutator(pkg, info, node)
+		if len(mutations) == 0 {
+			return nil
+		}
+
+		skip := false
+		for _, f := range filters {
+			if f.ShouldSkip(node, m.Name) {
+				skip = true
+
+				break
+			}
+		}
+
+		function := enclosingFunction(src, node)
+
+		var kept []mutator.Mutation
+		for _, mutation := range mutations {
+			id := ids.next(importPath, file, function, m.Name, mutationFingerprint(node, mutation))
+			if skip {
+				continue
+			}
+
+			*assigned = append(*assigned, id)
+			kept = append(kept, mutation)
+		}
+
+		return kept
+	}
+}
+
+// next returns the ID of a mutation with the given fingerprint in the given file and function.
+func (ids *mutantIDs) next(importPath string, file string, function string, mutatorName string, fingerprint string) string {
+	key := strings.Join([]string{importPath, filepath.Base(file), function, mutatorName, fingerprint}, "\n")
+
+	index := ids.seen[key]
+	ids.seen[key]++
+
+	return fmt.Sprintf("%x", md5.Sum([]byte(key+"\n"+strconv.Itoa(index))))
+}
+
+// mutationFingerprint returns the code which the mutation of the given node replaces, before and after the mutation.
+// Mutations which only change fields of nodes, e.g. an operator, replace no code, their whole node is used instead.
+// Positions are left out, so the fingerprint does not depend on where the code is.
+func mutationFingerprint(node ast.Node, mutation mutator.Mutation) string {
+	original := preorder(node)
+	mutation.Change()
+	mutated := preorder(node)
+
+	// Subtrees which are the same before and after the mutation surround the replaced ones
+	prefix := 0
+	for prefix < len(original.nodes) && prefix < len(mutated.nodes) && original.nodes[prefix] == mutated.nodes[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(original.nodes)-prefix && suffix < len(mutated.nodes)-prefix && original.nodes[len(original.nodes)-1-suffix] == mutated.nodes[len(mutated.nodes)-1-suffix] {
+		suffix++
+	}
+
+	replaced := prefix < len(original.nodes)-suffix || prefix < len(mutated.nodes)-suffix
+
+	var after string
+	if replaced {
+		after = mutated.source(prefix, len(mutated.nodes)-suffix)
+	} else {
+		after = printNode(node)
+	}
+
+	mutation.Reset()
+
+	var before string
+	if replaced {
+		before = original.source(prefix, len(original.nodes)-suffix)
+	} else {
+		before = printNode(node)
+	}
+
+	return before + "\n" + after
+}
+
+// nodeList nodes of a subtree in preorder with the index of their parent
+type nodeList struct {
+	nodes   []ast.Node
+	parents []int
+}
+
+func preorder(node ast.Node) nodeList {
+	var l nodeList
+	var stack []int
+
+	ast.Inspect(node, func(n ast.Node) bool {
+		if n == nil {
+			stack = stack[:len(stack)-1]
+
+			return true
+		}
+
+		parent := -1
+		if len(stack) > 0 {
+			parent = stack[len(stack)-1]
+		}
+
+		l.nodes = append(l.nodes, n)
+		l.parents = append(l.parents, parent)
+		stack = append(stack, len(l.nodes)-1)
+
+		return true
+	})
+
+	return l
+}
+
+// source returns the code of the subtrees which start in the given range of nodes and whose parent is outside of it.
+func (l nodeList) source(start int, end int) string {
+	var parts []string
+	for i := start; i < end; i++ {
+		if l.parents[i] < start {
+			parts = append(parts, printNode(l.nodes[i]))
+		}
+	}
+
+	return strings.Join(parts, "\n")
+}
+
+func printNode(node ast.Node) string {
+	var source bytes.Buffer
+	if err := printer.Fprint(&source, token.NewFileSet(), node); err != nil {
+		return fmt.Sprintf("%T", node)
+	}
+
+	return source.String()
+}
+
+// record remembers the ID of the mutation with the given checksum.
+func (ids *mutantIDs) record(checksum string, id string) {
+	ids.byChecksum[checksum] = id
+}
+
+// isBlacklisted returns if the blacklist contains the ID or the checksum of a mutation.
+// Checksums are what blacklists contained before mutations had IDs.
+func isBlacklisted(blacklist map[string]struct{}, id string, checksum string) bool {
+	if _, ok := blacklist[id]; ok {
+		return true
+	}
+	_, ok := blacklist[checksum]
+
+	return ok
+}
+
+// migrateBlacklist prints the entries of the given blacklist files with checksums replaced by the IDs of their mutations.
+// Checksums of mutations which no longer exist cannot be translated, they are left out.
+func migrateBlacklist(files []string, ids *mutantIDs) int {
+	known := make(map[string]struct{}, len(ids.byChecksum))
+	for _, id := range ids.byChecksum {
+		known[id] = struct{}{}
+	}
+
+	printed := make(map[string]struct{})
+	untranslated := 0
+
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return exitError("Cannot read blacklist file %q: %v", file, err)
+		}
+
+		for _, line := range strings.Split(string(content), "\n") {
+			if line == "" {
+				continue
+			}
+
+			id, ok := ids.byChecksum[line]
+			if !ok {
+				if _, ok := known[line]; !ok {
+					_, _ = fmt.Fprintf(os.Stderr, "%q of %q matches no mutation, it cannot be translated\n", line, file)
+					untranslated++
+
+					continue
+				}
+
+				// Already an ID
+				id = line
+			}
+
+			if _, ok := printed[id]; ok {
+				continue
+			}
+			printed[id] = struct{}{}
+
+			fmt.Println(id)
+		}
+	}
+
+	if untranslated > 0 {
+		_, _ = fmt.Fprintf(os.Stderr, "%d entries could not be translated, their mutations changed or do not exist anymore\n", untranslated)
+	}
+
+	return returnOk
+}
+
+// enclosingFunction returns the name of the function declaration which contains the given node, methods are prefixed with their receiver type.
+// Nodes outside of functions have no name.
+func enclosingFunction(file ast.Node, node ast.Node) string {
+	f, ok := file.(*ast.File)
+	if !ok {
+		return ""
+	}
+
+	for _, decl := range f.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || node.Pos() < fn.Pos() || node.Pos() >= fn.End() {
+			continue
+		}
+
+		if fn.Recv == nil || len(fn.Recv.List) == 0 {
+			return fn.Name.Name
+		}
+
+		return receiverType(fn.Recv.List[0].Type) + "." + fn.Name.Name
+	}
+
+	return ""
+}
+
+func receiverType(expr ast.Expr) string {
+	switch e := expr.(type) {
+	case *ast.StarExpr:
+		return receiverType(e.X)
+	case *ast.IndexExpr:
+		return receiverType(e.X)
+	case *ast.IndexListExpr:
+		return receiverType(e.X)
+	case *ast.Ident:
+		return e.Name
+	}
+
+	return ""
+}
@@ -11,22 +11,37 @@ import (
 	"go/types"
 	"io"
 	"log"
+	"math"
 	"os"
 	"os/exec"
+	"os/signal"
 	"path/filepath"
 	"regexp"
+	"slices"
+	"sort"
 	"strings"
 	"syscall"
+	"time"
 
 	"gopkg.in/yaml.v3"
 
 	"github.com/avito-tech/go-mutesting/internal/annotation"
+	"github.com/avito-tech/go-mutesting/internal/cache"
+	"github.com/avito-tech/go-mutesting/internal/checkpoint"
 	"github.com/avito-tech/go-mutesting/internal/console"
+	"github.com/avito-tech/go-mutesting/internal/coverage"
 	"github.com/avito-tech/go-mutesting/internal/filter"
+	"github.com/avito-tech/go-mutesting/internal/gate"
+	"github.com/avito-tech/go-mutesting/internal/gitdiff"
 	"github.com/avito-tech/go-mutesting/internal/importing"
+	"github.com/avito-tech/go-mutesting/internal/journal"
+	"github.com/avito-tech/go-mutesting/internal/killmatrix"
 	"github.com/avito-tech/go-mutesting/internal/models"
 	"github.com/avito-tech/go-mutesting/internal/parser"
 	"github.com/avito-tech/go-mutesting/internal/reportmaker"
+	"github.com/avito-tech/go-mutesting/internal/runner"
+	"github.com/avito-tech/go-mutesting/internal/sampling"
+	"github.com/avito-tech/go-mutesting/internal/schemata"
 	"github.com/jessevdk/go-flags"
 	"github.com/zimmski/osutil"
 
@@ -46,12 +61,21 @@ const (
 	returnHelp
 	returnBashCompletion
 	returnError
+	returnGateFailed
 )
 
+// minExecTimeout is the lower bound of timeouts which are derived from the duration of the tests on the original code.
+const minExecTimeout = 10 * time.Second
+
 func checkArguments(args []string, opts *models.Options) (bool, int) {
 	p := flags.NewNamedParser("go-mutesting", flags.None)
 
 	p.ShortDescription = "Mutation testing for Go source code"
+	p.LongDescription = "Commands (see \"go-mutesting <command> --help\"):\n" +
+		"restore: put back original source files which were displaced by an interrupted run\n" +
+		"cache-server: share the results of mutations between runs over HTTP\n" +
+		"merge: combine the JSON reports of several runs, e.g. of all shards, into one report\n" +
+		"accept: record the escaped mutations of a report in an accepted mutations file"
 
 	if _, err := p.AddGroup("go-mutesting", "go-mutesting arguments", opts); err != nil {
 		return true, exitError(err.Error())
@@ -95,9 +119,65 @@ func checkArguments(args []string, opts *models.Options) (bool, int) {
 		}
 	}
 
+	if opts.Test.KillMatrix && opts.Exec.Exec != "" {
+		return true, exitError("--kill-matrix needs the built-in exec command, custom exec commands do not report which tests failed")
+	}
+	if opts.Test.SelectTests && opts.Exec.Exec != "" {
+		return true, exitError("--select-tests needs the built-in exec command, custom exec commands always run their own tests")
+	}
+	if opts.Exec.Schemata && opts.Exec.Exec != "" {
+		return true, exitError("--schemata needs the built-in exec command, custom exec commands build the mutations on their own")
+	}
+	if opts.Exec.Schemata && opts.Test.Recursive {
+		return true, exitError("--schemata cannot be combined with --test-recursive, the test binary only contains the tests of the mutated package")
+	}
+	if opts.Test.Scope == scopeDependents && opts.Exec.Exec != "" {
+		return true, exitError("--test-scope dependents needs the built-in exec command, custom exec commands always run their own tests")
+	}
+	if opts.Test.Scope == scopeDependents && opts.Test.SelectTests {
+		return true, exitError("--test-scope dependents cannot be combined with --select-tests, tests are only selected from the mutated package")
+	}
+	if opts.Test.Scope == scopeDependents && opts.Exec.Schemata {
+		return true, exitError("--test-scope dependents cannot be combined with --schemata, the test binary only contains the tests of the mutated package")
+	}
+	if len(opts.Test.Consumers) > 0 && opts.Exec.Exec != "" {
+		return true, exitError("--consumer needs the built-in exec command, custom exec commands always run their own tests")
+	}
+	if len(opts.Test.Consumers) > 0 && opts.Test.SelectTests {
+		return true, exitError("--consumer cannot be combined with --select-tests, tests are only selected from the mutated module")
+	}
+	if len(opts.Test.Consumers) > 0 && (opts.Cache.Dir != "" || opts.Cache.URL != "") {
+		return true, exitError("--consumer cannot be combined with the result cache, changes of consumer modules do not invalidate cached results")
+	}
+	if opts.Test.SelectTests && opts.Test.NoBaseline {
+		return true, exitError("--select-tests needs the baseline run to find the tests of every package")
+	}
+	if opts.Filter.Shard != "" {
+		if _, _, err := parseShard(opts.Filter.Shard); err != nil {
+			return true, exitError(err.Error())
+		}
+	}
+	if opts.Filter.Sample != "" {
+		if _, err := sampling.Size(opts.Filter.Sample, 0); err != nil {
+			return true, exitError(err.Error())
+		}
+	}
+	if err := checkStages(opts); err != nil {
+		return true, exitError(err.Error())
+	}
+
 	return false, 0
 }
 
+// matchMutator returns if the given mutator name matches the pattern, a name or a prefix followed by * or /* (e.g. branch/*).
+func matchMutator(pattern string, name string) bool {
+	if prefix, ok := strings.CutSuffix(pattern, "*"); ok {
+		return strings.HasPrefix(name, strings.TrimSuffix(prefix, "/"))
+	}
+
+	return name == pattern
+}
+
 func exitError(format string, args ...interface{}) int {
 	_, _ = fmt.Fprintf(os.Stderr, format+"\n", args...)
 
@@ -111,17 +191,70 @@ type mutatorItem struct {
 
 func mainCmd(args []string) int {
 	var opts = &models.Options{}
+	// mutationBlackList holds the IDs and legacy checksums of blacklisted mutations
 	var mutationBlackList = map[string]struct{}{}
 
+	if len(args) > 0 && args[0] == "restore" {
+		return restoreCmd(args[1:])
+	}
+	if len(args) > 0 && args[0] == "cache-server" {
+		return cacheServerCmd(args[1:])
+	}
+	if len(args) > 0 && args[0] == "merge" {
+		return mergeCmd(args[1:])
+	}
+	if len(args) > 0 && args[0] == "accept" {
+		return acceptCmd(args[1:])
+	}
+
 	if exit, exitCode := checkArguments(args, opts); exit {
 		return exitCode
 	}
 
+	start := time.Now()
+
+	var changed map[string][]int64
+	if opts.Filter.ChangedSince != "" {
+		var err error
+		changed, err = gitdiff.ChangedLines(opts.Filter.ChangedSince)
+		if err != nil {
+			return exitError("Could not read the changes since %q: %v", opts.Filter.ChangedSince, err)
+		}
+	}
+
+	var escaped map[string]models.Mutant
+	if opts.Filter.RerunEscaped != "" {
+		var err error
+		escaped, err = loadEscaped(opts.Filter.RerunEscaped)
+		if err != nil {
+			return exitError("Could not read report %q: %v", opts.Filter.RerunEscaped, err)
+		}
+	}
+
 	files := importing.FilesOfArgs(opts.Remaining.Targets, opts)
-	if len(files) == 0 {
+	if changed != nil {
+		files = changedFiles(files, changed)
+	}
+	if escaped != nil {
+		// Only files with escaped mutations have to be mutated again
+		files = escapedFiles(files, escaped)
+	}
+	if len(files) == 0 && changed != nil {
+		fmt.Printf("No Go source files changed since %q, there is nothing to mutate\n", opts.Filter.ChangedSince)
+
+		return returnOk
+	} else if len(files) == 0 && escaped != nil {
+		fmt.Printf("None of the targets contains a mutation which escaped in %q, there is nothing to test\n", opts.Filter.RerunEscaped)
+
+		return returnOk
+	} else if len(files) == 0 {
 		return exitError("Could not find any suitable Go source files")
 	}
 
+	if err := checkInterruptedRuns(files); err != nil {
+		return exitError(err.Error())
+	}
+
 	if opts.Files.ListFiles {
 		for _, file := range files {
 			fmt.Println(file)
@@ -158,7 +291,7 @@ func mainCmd(args []string) int {
 				}
 
 				if len(line) != 32 {
-					return exitError("%q is not a MD5 checksum", line)
+					return exitError("%q is not a mutation ID or MD5 checksum", line)
 				}
 
 				mutationBlackList[line] = struct{}{}
@@ -166,15 +299,22 @@ func mainCmd(args []string) int {
 		}
 	}
 
+	var acceptedMutations map[string]models.Acceptance
+	if opts.Files.Accepted != "" {
+		var err error
+		acceptedMutations, err = loadAccepted(opts.Files.Accepted)
+		if err != nil {
+			return exitError("Could not read accepted mutations: %v", err)
+		}
+	}
+
 	var mutators []mutatorItem
 
 MUTATOR:
 	for _, name := range mutator.List() {
 		if len(opts.Mutator.DisableMutators) > 0 {
 			for _, d := range opts.Mutator.DisableMutators {
-				pattern := strings.HasSuffix(d, "*")
-
-				if (pattern && strings.HasPrefix(name, d[:len(d)-2])) || (!pattern && name == d) {
+				if matchMutator(d, name) {
 					continue MUTATOR
 				}
 			}
@@ -195,12 +335,32 @@ MUTATOR:
 	}
 	console.Verbose(opts, "Save mutations into %q", tmpDir)
 
+	swaps, err := journal.Create(journal.PathFor(tmpDir))
+	if err != nil {
+		panic(err)
+	}
+	console.Debug(opts, "Record displaced files into %q", journal.PathFor(tmpDir))
+
+	stopRestoreOnSignal := restoreOnSignal(swaps)
+	defer stopRestoreOnSignal()
+
 	var execs []string
 	if opts.Exec.Exec != "" {
 		execs = strings.Split(opts.Exec.Exec, " ")
 	}
 
 	report := &models.Report{}
+	// random collects the results of the mutations which were tested in a random order, see estimateMsi
+	var random models.Report
+	var jobs []mutantJob
+	// importPaths of the mutated packages by their path, packages whose import path cannot be resolved are left out
+	importPaths := make(map[string]string)
+	unresolved := make(map[string]struct{})
+	ids := newMutantIDs(importPaths)
+	// checksums of all saved mutations, a mutation with a known checksum is a duplicate
+	checksums := make(map[string]struct{})
+	// schemas holds the schemata of every mutated file if --schemata is set
+	schemas := make(map[string]*schemata.File)
 
 	for _, file := range files {
 		console.Verbose(opts, "Mutate %q", file)
@@ -218,16 +378,36 @@ MUTATOR:
 			skipFilterProcessor,
 		}
 
+		if changed != nil {
+			changedLinesFilter := filter.NewChangedLinesFilter(changed)
+
+			collectors = append(collectors, changedLinesFilter)
+			filters = append(filters, changedLinesFilter)
+		}
+
 		src, fset, pkg, info, err := parser.ParseAndTypeCheckFile(file, collectors)
 		if err != nil {
 			return exitError(err.Error())
 		}
 
+		resolveImportPath(importPaths, unresolved, pkg.Path())
+
 		err = os.MkdirAll(tmpDir+"/"+filepath.Dir(file), 0755)
 		if err != nil {
 			panic(err)
 		}
 
+		var schema *schemata.File
+		if opts.Exec.Schemata {
+			content, err := os.ReadFile(file)
+			if err != nil {
+				return exitError("Could not read file %q: %v", file, err)
+			}
+
+			schema = schemata.NewFile(fset, src, content)
+			schemas[file] = schema
+		}
+
 		tmpFile := tmpDir + "/" + file
 
 		originalFile := fmt.Sprintf("%s.original", tmpFile)
@@ -247,14 +427,201 @@ MUTATOR:
 
 			for _, f := range astutil.Functions(src) {
 				if m.MatchString(f.Name.Name) {
-					mutationID = mutate(opts, mutators, mutationBlackList, mutationID, pkg, info, file, fset, src, f, tmpFile, execs, report, filters)
+					var fileJobs []mutantJob
+					mutationID, fileJobs = mutate(opts, mutators, mutationBlackList, checksums, ids, mutationID, pkg, info, file, originalFile, fset, src, f, tmpFile, report, filters, schema)
+					jobs = append(jobs, fileJobs...)
 				}
 			}
 		} else {
-			_ = mutate(opts, mutators, mutationBlackList, mutationID, pkg, info, file, fset, src, src, tmpFile, execs, report, filters)
+			_, fileJobs := mutate(opts, mutators, mutationBlackList, checksums, ids, mutationID, pkg, info, file, originalFile, fset, src, src, tmpFile, report, filters, schema)
+			jobs = append(jobs, fileJobs...)
+		}
+	}
+
+	if changed != nil {
+		count := len(jobs)
+		jobs = changedLineJobs(jobs, changed)
+		console.Verbose(opts, "Skip %d mutations of blocks with changed lines which do not change a changed line themselves", count-len(jobs))
+	}
+
+	var stale []models.Mutant
+	if escaped != nil {
+		jobs, stale = rerunJobs(jobs, escaped)
+	}
+
+	if opts.Filter.Shard != "" {
+		k, n, _ := parseShard(opts.Filter.Shard)
+
+		sharded := shardJobs(jobs, k, n)
+		fmt.Printf("Shard %d/%d tests %d of %d mutations\n", k, n, len(sharded), len(jobs))
+
+		jobs = sharded
+	}
+
+	if opts.Filter.Sample != "" || opts.Exec.TimeBudget > 0 {
+		report.Sampling = &models.Sampling{
+			Sample:     opts.Filter.Sample,
+			Seed:       sampleSeed(opts),
+			Population: len(jobs),
+			Sampled:    len(jobs),
+		}
+		if opts.Exec.TimeBudget > 0 {
+			report.Sampling.TimeBudget = opts.Exec.TimeBudget.String()
 		}
 	}
 
+	if opts.Filter.Sample != "" {
+		size, _ := sampling.Size(opts.Filter.Sample, len(jobs))
+
+		jobs = sampleJobs(jobs, size, report.Sampling.Seed)
+		fmt.Printf("Sample %d of %d mutations with seed %d\n", len(jobs), report.Sampling.Population, report.Sampling.Seed)
+
+		report.Sampling.Sampled = len(jobs)
+	}
+
+	if !opts.Exec.NoExec && !opts.Files.MigrateBlacklist {
+		if opts.Exec.Resume != "" {
+			resumed, err := checkpoint.Load(opts.Exec.Resume)
+			if err != nil {
+				return exitError("Could not read checkpoint %q: %v", opts.Exec.Resume, err)
+			}
+
+			jobs = resumeJobs(opts, jobs, resumed, report, &random)
+		}
+
+		checkpointFile := opts.Exec.Checkpoint
+		if checkpointFile == "" {
+			checkpointFile = opts.Exec.Resume
+		}
+
+		var checkpoints *checkpoint.Writer
+		if checkpointFile != "" {
+			checkpoints, err = checkpoint.Open(checkpointFile)
+			if err != nil {
+				return exitError("Could not open checkpoint %q: %v", checkpointFile, err)
+			}
+			defer func() {
+				_ = checkpoints.Close()
+			}()
+		}
+
+		var baselines map[string]models.Baseline
+		var profiles map[string]coverage.Profile
+
+		if opts.Test.Scope == scopeDependents {
+			assignDependents(opts, jobs, importPaths)
+		}
+
+		if !opts.Test.NoBaseline {
+			baselines, profiles = testBaselines(opts, tmpDir, jobs, execs, swaps, importPaths)
+
+			var failing []string
+			for _, b := range baselines {
+				report.Baselines = append(report.Baselines, b)
+
+				if !b.Passed {
+					failing = append(failing, b.Package)
+				}
+			}
+			sort.Strings(failing)
+			sort.Slice(report.Baselines, func(i, j int) bool {
+				return report.Baselines[i].Package < report.Baselines[j].Package
+			})
+
+			if len(failing) > 0 {
+				if !opts.Test.ExcludeFailingPackages {
+					return exitError("The tests of the following packages do not pass on the original code, fix them or use --exclude-failing-packages: %s", strings.Join(failing, ", "))
+				}
+
+				fmt.Printf("Exclude the following packages since their tests do not pass on the original code: %s\n", strings.Join(failing, ", "))
+
+				jobs = slices.DeleteFunc(jobs, func(job mutantJob) bool {
+					return !baselines[job.pkg.Path()].Passed
+				})
+			}
+		}
+
+		if opts.Test.CoverProfile != "" {
+			profile, err := coverage.ReadProfile(opts.Test.CoverProfile)
+			if err != nil {
+				return exitError("Could not read coverage profile %q: %v", opts.Test.CoverProfile, err)
+			}
+
+			profiles = make(map[string]coverage.Profile)
+			for _, pkgName := range sortedPackages(jobs) {
+				profiles[pkgName] = profile
+			}
+		}
+
+		markNotCovered(opts, jobs, profiles, importPaths)
+
+		if acceptedMutations != nil {
+			markAccepted(jobs, acceptedMutations)
+		}
+
+		timeouts := execTimeouts(opts, jobs, baselines)
+
+		if opts.Test.SelectTests {
+			selectTests(opts, tmpDir, jobs, baselines, timeouts, importPaths)
+		}
+
+		var results cache.Store
+		if opts.Cache.Dir != "" || opts.Cache.URL != "" {
+			results, err = openCache(opts)
+			if err != nil {
+				return exitError(err.Error())
+			}
+
+			cacheKeys(opts, jobs)
+			lookupCache(opts, results, jobs)
+		}
+
+		if opts.Exec.Schemata {
+			buildSchemata(opts, tmpDir, jobs, schemas, importPaths)
+		}
+
+		if len(opts.Test.Consumers) > 0 {
+			consumers, err := prepareConsumers(opts, tmpDir, jobs, importPaths)
+			if err != nil {
+				return exitError(err.Error())
+			}
+
+			for i := range jobs {
+				jobs[i].consumers = consumers
+			}
+		}
+
+		var deadline time.Time
+		if opts.Exec.TimeBudget > 0 {
+			deadline = start.Add(opts.Exec.TimeBudget)
+			jobs = prioritizeJobs(jobs, report.Sampling.Seed)
+
+			for _, job := range jobs {
+				if prioritized(job) {
+					report.Sampling.Prioritized++
+				}
+			}
+		}
+
+		overBudget := runMutants(opts, jobs, execs, timeouts, swaps, results, checkpoints, deadline, report, &random)
+		if report.Sampling != nil {
+			report.Sampling.OverBudget = overBudget
+		}
+
+		if opts.Test.KillMatrix {
+			report.KillMatrix = killmatrix.Build(*report)
+		}
+
+		if escaped != nil {
+			report.Rerun = rerunDelta(opts.Filter.RerunEscaped, report, escaped, stale)
+		}
+	}
+
+	err = swaps.Close()
+	if err != nil {
+		return exitError(err.Error())
+	}
+
 	if !opts.General.DoNotRemoveTmpFolder {
 		err = os.RemoveAll(tmpDir)
 		if err != nil {
@@ -263,7 +630,14 @@ MUTATOR:
 		console.Debug(opts, "Remove %q", tmpDir)
 	}
 
+	if opts.Files.MigrateBlacklist {
+		return migrateBlacklist(opts.Files.Blacklist, ids)
+	}
+
 	report.Calculate()
+	if report.Sampling != nil && !opts.Exec.NoExec {
+		estimateMsi(report.Sampling, &random)
+	}
 
 	if !opts.Exec.NoExec {
 		if !opts.Config.SilentMode {
@@ -275,6 +649,38 @@ MUTATOR:
 				report.Stats.SkippedCount,
 				report.Stats.TotalMutantsCount,
 			)
+
+			if details := summaryDetails(report); len(details) > 0 {
+				fmt.Printf("Of the total, %s\n", strings.Join(details, ", "))
+			}
+			if report.Stats.NotCoveredCount > 0 {
+				fmt.Printf("The covered code mutation score is %f, the mutation code coverage is %f\n",
+					report.Stats.CoveredCodeMsi,
+					report.Stats.MutationCodeCoverage,
+				)
+			}
+			if excluded := summaryExcluded(report); len(excluded) > 0 {
+				fmt.Printf("Not part of the total, %s\n", strings.Join(excluded, ", "))
+			}
+			if opts.Filter.ChangedSince != "" {
+				fmt.Printf("The changed-lines mutation score is %f (%d mutations of lines changed since %s)\n",
+					report.Stats.ChangedLinesMsi,
+					report.Stats.ChangedLinesCount,
+					opts.Filter.ChangedSince,
+				)
+			}
+			if report.Stats.CachedCount > 0 {
+				fmt.Printf("%d results were reused from the cache\n", report.Stats.CachedCount)
+			}
+			if report.KillMatrix != nil {
+				printKillMatrix(report.KillMatrix)
+			}
+			if report.Rerun != nil {
+				printRerunDelta(report.Rerun)
+			}
+			if report.Sampling != nil {
+				printSampling(report.Sampling)
+			}
 		}
 	} else {
 		fmt.Println("Cannot do a mutation testing summary since no exec command was executed.")
@@ -296,31 +702,101 @@ MUTATOR:
 		console.Verbose(opts, "Save report into %q", models.ReportHTMLFileName)
 	}
 
+	if !opts.Exec.NoExec {
+		return checkGates(*report, opts.Config.Gates, opts.Gate)
+	}
+
 	return returnOk
 }
 
+type mutantJob struct {
+	mutator string
+	pkg     *types.Package
+	// importPath of the package, or its path if it could not be resolved
+	importPath   string
+	originalFile string
+	originalCopy string
+	mutationFile string
+	checksum     string
+	// id identifies the mutation across runs, see mutantIDs
+	id string
+	// function which contains the mutation, methods are prefixed with their receiver type
+	function string
+	// tests which cover the mutation, all tests are run if there are none
+	tests []models.Test
+	// testFlags additional arguments of go test, e.g. of a test stage
+	testFlags []string
+	// dependents import paths of the packages whose tests are run as well, see --test-scope
+	dependents []string
+	// consumers modules whose tests test the mutation if it survives the tests of the module, see --consumer
+	consumers []*consumer
+	// notCovered is set if no test executes the mutated code, such mutations are not tested
+	notCovered bool
+	// coverPackage and coverProfile record the coverage of the package with the given import path into the profile
+	coverPackage string
+	coverProfile string
+	// schemaID activates the mutation in the schemata test binary of its package, see --schemata
+	schemaID      string
+	schemaBinary  string
+	schemaPackage string
+	// cacheKey addresses the result of the mutation in the cache, cached is set if it was found there
+	cacheKey string
+	cached   *cache.Entry
+	// onChangedLines is set if the mutation changes a line which changed since the ref of --changed-since
+	onChangedLines bool
+	// acceptance is set if the mutation is listed in the accepted mutations file, it only applies if the mutation survives
+	acceptance *models.Acceptance
+}
+
+type mutantResult struct {
+	mutant models.Mutant
+	// status of the mutation as tested, the status of the mutant is accepted instead if it survived and is accepted
+	status models.MutantStatus
+	output []byte
+	// overBudget is set if the mutation was not tested since the time budget ran out
+	overBudget bool
+}
+
+type baselineResult struct {
+	result runner.TestResult
+	// stage whose tests did not pass, see baselineStages
+	stage    string
+	duration time.Duration
+	output   []byte
+	profile  coverage.Profile
+}
+
 func mutate(
 	opts *models.Options,
 	mutators []mutatorItem,
 	mutationBlackList map[string]struct{},
+	checksums map[string]struct{},
+	ids *mutantIDs,
 	mutationID int,
 	pkg *types.Package,
 	info *types.Info,
 	originalFile string,
+	originalCopy string,
 	fset *token.FileSet,
 	src ast.Node,
 	node ast.Node,
 	mutatedFile string,
-	execs []string,
 	stats *models.Report,
 	filters []filter.NodeFilter,
-) int {
+	schema *schemata.File,
+) (int, []mutantJob) {
+	var jobs []mutantJob
+
+	importPath := ids.importPath(pkg.Path())
+
 	for _, m := range mutators {
 		console.Debug(opts, "Mutator %s", m.Name)
 
-		mutatorAnnotated := annotation.DecoratorFilter(m.Mutator, m.Name, filters...)
+		// IDs of the generated mutations in the order in which they are applied
+		var assigned []string
+		mutatorIdentified := ids.mutator(m, importPath, originalFile, src, filters, &assigned)
 
-		changed := mutesting.MutateWalk(pkg, info, node, mutatorAnnotated)
+		changed, mutatedNode := mutesting.MutateWalkNodes(pkg, info, node, mutatorIdentified)
 
 		for {
 			_, ok := <-changed
@@ -329,78 +805,47 @@ func mutate(
 				break
 			}
 
-			originalSourceCode, err := os.ReadFile(originalFile)
-			if err != nil {
-				log.Fatal(err)
-			}
-
-			mutant := models.Mutant{}
-			mutant.Mutator.MutatorName = m.Name
-			mutant.Mutator.OriginalFilePath = originalFile
-			mutant.Mutator.OriginalSourceCode = string(originalSourceCode)
-
 			mutationFile := fmt.Sprintf("%s.%d", mutatedFile, mutationID)
-			checksum, duplicate, err := saveAST(mutationBlackList, mutationFile, fset, src)
+			mutated := mutatedNode()
+			function := enclosingFunction(src, mutated)
+			// Every mutation takes an ID, so blacklisting a mutation does not change the IDs of others
+			id := assigned[0]
+			assigned = assigned[1:]
+
+			checksum, duplicate, err := saveAST(checksums, mutationFile, fset, src)
 			if err != nil {
 				fmt.Printf("INTERNAL ERROR %s\n", err.Error())
 			} else if duplicate {
 				console.Debug(opts, "%q is a duplicate, we ignore it", mutationFile)
 
+				stats.Stats.DuplicatedCount++
+			} else if isBlacklisted(mutationBlackList, id, checksum) {
+				ids.record(checksum, id)
+				console.Debug(opts, "%q with ID %s is blacklisted, we ignore it", mutationFile, id)
+
 				stats.Stats.DuplicatedCount++
 			} else {
-				console.Debug(opts, "Save mutation into %q with checksum %s", mutationFile, checksum)
-
-				if !opts.Exec.NoExec {
-					execExitCode := mutateExec(opts, pkg, originalFile, src, mutationFile, execs, &mutant)
-
-					console.Debug(opts, "Exited with %d", execExitCode)
-
-					mutatedSourceCode, err := os.ReadFile(mutationFile)
-					if err != nil {
-						log.Fatal(err)
-					}
-					mutant.Mutator.MutatedSourceCode = string(mutatedSourceCode)
-
-					msg := fmt.Sprintf("%q with checksum %s", mutationFile, checksum)
-
-					switch execExitCode {
-					case 0: // Tests failed - all ok
-						out := fmt.Sprintf("PASS %s\n", msg)
-						if !opts.Config.SilentMode {
-							console.PrintPass(out)
-						}
-
-						mutant.ProcessOutput = out
-						stats.Killed = append(stats.Killed, mutant)
-						stats.Stats.KilledCount++
-					case 1: // Tests passed
-						out := fmt.Sprintf("FAIL %s\n", msg)
-						if !opts.Config.SilentMode {
-							console.PrintFail(out)
-						}
-
-						mutant.ProcessOutput = out
-						stats.Escaped = append(stats.Escaped, mutant)
-						stats.Stats.EscapedCount++
-					case 2: // Did not compile
-						out := fmt.Sprintf("SKIP %s\n", msg)
-						if !opts.Config.SilentMode {
-							console.PrintSkip(out)
-						}
-
-						mutant.ProcessOutput = out
-						stats.Stats.SkippedCount++
-					default:
-						out := fmt.Sprintf("UNKOWN exit code for %s\n", msg)
-						if !opts.Config.SilentMode {
-							console.PrintUnknown(out)
-						}
-
-						mutant.ProcessOutput = out
-						stats.Errored = append(stats.Errored, mutant)
-						stats.Stats.ErrorCount++
-					}
+				ids.record(checksum, id)
+				console.Debug(opts, "Save mutation into %q with ID %s and checksum %s", mutationFile, id, checksum)
+
+				job := mutantJob{
+					mutator:      m.Name,
+					pkg:          pkg,
+					importPath:   importPath,
+					originalFile: originalFile,
+					originalCopy: originalCopy,
+					mutationFile: mutationFile,
+					checksum:     checksum,
+					id:           id,
+					function:     function,
+				}
+
+				// IDs only have to be unique within the package of the mutation
+				if schema != nil && schema.Add(filepath.Base(mutationFile), mutatedNode()) {
+					job.schemaID = filepath.Base(mutationFile)
 				}
+
+				jobs = append(jobs, job)
 			}
 
 			changed <- true
@@ -413,150 +858,686 @@ func mutate(
 		}
 	}
 
-	return mutationID
+	return mutationID, jobs
 }
 
-func mutateExec(
+func runMutants(
 	opts *models.Options,
-	pkg *types.Package,
-	file string,
-	src ast.Node,
-	mutationFile string,
+	jobs []mutantJob,
 	execs []string,
-	mutant *models.Mutant,
-) (execExitCode int) {
+	timeouts map[string]time.Duration,
+	swaps *journal.Journal,
+	results cache.Store,
+	checkpoints *checkpoint.Writer,
+	deadline time.Time,
+	stats *models.Report,
+	random *models.Report,
+) (overBudget int) {
+	workers := runner.Workers(opts.Exec.Jobs)
+	console.Verbose(opts, "Execute %d mutations with %d workers", len(jobs), workers)
+
+	packageLocks := runner.NewKeyedMutex()
+
+	runner.Run(workers, len(jobs), func(i int) mutantResult {
+		job := jobs[i]
+
+		// Mutations which already started are finished, even if that takes longer than the budget.
+		// Cached results cost nothing, so they are always counted.
+		if !deadline.IsZero() && job.cached == nil && time.Now().After(deadline) {
+			return mutantResult{overBudget: true}
+		}
+
+		unlock := lockPackage(opts, packageLocks, job, execs)
+		defer unlock()
+
+		return executeMutant(opts, job, timeouts[job.pkg.Path()], execs, swaps)
+	}, func(i int, result mutantResult) {
+		if result.overBudget {
+			overBudget++
+
+			return
+		}
+
+		_, _ = os.Stdout.Write(result.output)
+
+		stats.Add(result.mutant)
+
+		job := jobs[i]
+		if deadline.IsZero() || !prioritized(job) {
+			random.Add(result.mutant)
+		}
+		if results != nil && job.cacheKey != "" && job.cached == nil && cache.Cacheable(result.status) {
+			err := results.Put(job.cacheKey, cache.Entry{
+				Status:            result.status,
+				KilledBy:          result.mutant.KilledBy,
+				Diff:              result.mutant.Diff,
+				OriginalStartLine: result.mutant.Mutator.OriginalStartLine,
+				Stage:             result.mutant.Stage,
+			})
+			if err != nil {
+				fmt.Printf("Could not cache the result of %q, the failing cache is not used anymore: %v\n", job.mutationFile, err)
+			}
+		}
+
+		if checkpoints != nil {
+			if err := checkpoints.Append(job.id, result.mutant); err != nil {
+				fmt.Printf("Could not write the checkpoint, the run cannot be resumed from here on: %v\n", err)
+
+				checkpoints = nil
+			}
+		}
+	})
+
+	return overBudget
+}
+
+// lockPackage makes sure that mutations which are swapped into the same package by an exec command do not overlap.
+func lockPackage(opts *models.Options, packageLocks *runner.KeyedMutex, job mutantJob, execs []string) (unlock func()) {
 	if len(execs) == 0 {
-		console.Debug(opts, "Execute built-in exec command for mutation")
+		// The built-in exec command uses overlays and never touches the package
+		return func() {}
+	}
 
-		diff, err := exec.Command("diff", "--label=Original", "--label=New", "-u", file, mutationFile).CombinedOutput()
+	lockKey := job.pkg.Path()
+	if opts.Test.Recursive {
+		lockKey = ""
+	}
 
-		startLine := parser.FindOriginalStartLine(diff)
-		mutant.Mutator.OriginalStartLine = startLine
+	return packageLocks.Lock(lockKey)
+}
+
+// testBaselines executes the exec command once with the original code of every package of the given mutations.
+// Mutations can only be judged if the tests pass on the original code, and their duration is the base for timeouts.
+// The built-in exec command also records the coverage of every package, unless a coverage profile is given.
+func testBaselines(
+	opts *models.Options,
+	tmpDir string,
+	jobs []mutantJob,
+	execs []string,
+	swaps *journal.Journal,
+	importPaths map[string]string,
+) (map[string]models.Baseline, map[string]coverage.Profile) {
+	var originals []mutantJob
+	seen := make(map[string]struct{})
+	for _, job := range jobs {
+		if _, ok := seen[job.pkg.Path()]; ok {
+			continue
+		}
+		seen[job.pkg.Path()] = struct{}{}
+
+		// The unchanged copy of the original file is tested like a mutation
+		original := mutantJob{
+			pkg:          job.pkg,
+			originalFile: job.originalFile,
+			originalCopy: job.originalCopy,
+			mutationFile: job.originalCopy,
+			dependents:   job.dependents,
+		}
+
+		if importPath, ok := importPaths[job.pkg.Path()]; ok && len(execs) == 0 && opts.Test.CoverProfile == "" {
+			original.coverPackage = importPath
+			original.coverProfile = filepath.Join(tmpDir, fmt.Sprintf("baseline.%d.cover", len(originals)))
+		}
+
+		originals = append(originals, original)
+	}
+
+	baselines := make(map[string]models.Baseline, len(originals))
+	profiles := make(map[string]coverage.Profile)
+	packageLocks := runner.NewKeyedMutex()
+
+	runner.Run(runner.Workers(opts.Exec.Jobs), len(originals), func(i int) baselineResult {
+		job := originals[i]
+
+		unlock := lockPackage(opts, packageLocks, job, execs)
+		defer unlock()
+
+		var out bytes.Buffer
+
+		var result runner.TestResult
+		var stage string
+		var duration time.Duration
 
-		if err == nil {
-			execExitCode = 0
-		} else if e, ok := err.(*exec.ExitError); ok {
-			execExitCode = e.Sys().(syscall.WaitStatus).ExitStatus()
+		if len(opts.Config.Stages) > 0 {
+			// A stage which fails on the original code would kill every mutation which reaches it
+			result, stage, duration = baselineStages(opts, &out, job, execs, swaps)
 		} else {
-			panic(err)
+			// Without an explicit timeout there is nothing yet to derive one from
+			timeout := time.Duration(opts.Exec.Timeout) * time.Second
+
+			start := time.Now()
+			result = mutateExec(opts, &out, job, timeout, execs, swaps, &models.Mutant{})
+
+			duration = time.Since(start)
 		}
-		if execExitCode != 0 && execExitCode != 1 {
-			fmt.Printf("%s\n", diff)
 
-			panic("Could not execute diff on mutation file")
+		var profile coverage.Profile
+		if job.coverProfile != "" && result.Status == models.StatusEscaped {
+			var err error
+			profile, err = coverage.ReadProfile(job.coverProfile)
+			if err != nil {
+				_, _ = fmt.Fprintf(&out, "Could not read the coverage of %q: %v\n", job.pkg.Path(), err)
+			}
 		}
 
-		defer func() {
-			_ = os.Rename(file+".tmp", file)
-		}()
+		return baselineResult{
+			result:   result,
+			stage:    stage,
+			duration: duration,
+			output:   out.Bytes(),
+			profile:  profile,
+		}
+	}, func(i int, result baselineResult) {
+		pkgName := originals[i].pkg.Path()
 
-		err = os.Rename(file, file+".tmp")
-		if err != nil {
-			panic(err)
+		if result.profile != nil {
+			profiles[pkgName] = result.profile
 		}
-		err = osutil.CopyFile(mutationFile, file)
-		if err != nil {
-			panic(err)
+
+		// The original code is alive, if the tests pass
+		passed := result.result.Status == models.StatusEscaped
+
+		baselines[pkgName] = models.Baseline{
+			Package:  pkgName,
+			Duration: result.duration.Seconds(),
+			Passed:   passed,
+			Stage:    result.stage,
+			Tests:    result.result.Tests,
 		}
 
-		pkgName := pkg.Path()
-		if opts.Test.Recursive {
-			pkgName += "/..."
+		if passed {
+			console.Verbose(opts, "Tests of %q pass on the original code in %s", pkgName, result.duration.Round(time.Millisecond))
+		} else if result.stage != "" {
+			_, _ = os.Stdout.Write(result.output)
+			fmt.Printf("Tests of %q do not pass on the original code in stage %s (%s)\n", pkgName, result.stage, result.result.Status)
+		} else {
+			_, _ = os.Stdout.Write(result.output)
+			fmt.Printf("Tests of %q do not pass on the original code (%s)\n", pkgName, result.result.Status)
 		}
+	})
 
-		goTestCmd := exec.Command("go", "test", "-timeout", fmt.Sprintf("%ds", opts.Exec.Timeout), pkgName)
-		goTestCmd.Env = os.Environ()
+	return baselines, profiles
+}
+
+// execTimeouts returns the timeout of the exec command for every package of the given mutations.
+// Without an explicit timeout it is derived from the duration the exec command took on the original code of the package.
+func execTimeouts(opts *models.Options, jobs []mutantJob, baselines map[string]models.Baseline) map[string]time.Duration {
+	timeouts := make(map[string]time.Duration)
+
+	for _, job := range jobs {
+		pkgName := job.pkg.Path()
+		if _, ok := timeouts[pkgName]; ok {
+			continue
+		}
 
-		test, err := goTestCmd.CombinedOutput()
-		if err == nil {
-			execExitCode = 0
-		} else if e, ok := err.(*exec.ExitError); ok {
-			execExitCode = e.Sys().(syscall.WaitStatus).ExitStatus()
+		if opts.Exec.Timeout > 0 {
+			timeouts[pkgName] = time.Duration(opts.Exec.Timeout) * time.Second
+		} else if b, ok := baselines[pkgName]; ok {
+			baseline := time.Duration(b.Duration * float64(time.Second))
+			timeouts[pkgName] = runner.Timeout(baseline, opts.Exec.TimeoutFactor, minExecTimeout)
 		} else {
+			timeouts[pkgName] = minExecTimeout
+		}
+
+		console.Debug(opts, "Use a timeout of %s for %q", timeouts[pkgName].Round(time.Millisecond), pkgName)
+	}
+
+	return timeouts
+}
+
+func executeMutant(opts *models.Options, job mutantJob, timeout time.Duration, execs []string, swaps *journal.Journal) mutantResult {
+	var out bytes.Buffer
+
+	originalSourceCode, err := os.ReadFile(job.originalFile)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	mutant := models.Mutant{}
+	mutant.Mutator.MutatorName = job.mutator
+	mutant.Mutator.OriginalFilePath = job.originalFile
+	mutant.Mutator.OriginalSourceCode = string(originalSourceCode)
+	mutant.Checksum = job.checksum
+	mutant.ID = job.id
+	mutant.Package = job.importPath
+	mutant.Function = job.function
+	mutant.OnChangedLines = job.onChangedLines
+
+	var result runner.TestResult
+	if job.cached != nil {
+		// Nothing the result depends on changed since it was cached
+		mutant.Diff = job.cached.Diff
+		mutant.Mutator.OriginalStartLine = job.cached.OriginalStartLine
+		mutant.Cached = true
+
+		mutant.Stage = job.cached.Stage
+
+		result.Status = job.cached.Status
+		result.FailedTests = job.cached.KilledBy
+	} else if len(opts.Config.Stages) > 0 {
+		result = mutateStages(opts, &out, job, timeout, execs, swaps, &mutant)
+	} else if job.notCovered {
+		// There is no test which could kill the mutation
+		diff := mutationDiff(job.originalFile, job.mutationFile)
+		mutant.Diff = string(diff)
+		mutant.Mutator.OriginalStartLine = parser.FindOriginalStartLine(diff)
+
+		result.Status = models.StatusNotCovered
+	} else {
+		result = mutateExec(opts, &out, job, timeout, execs, swaps, &mutant)
+	}
+	if len(job.consumers) > 0 && job.cached == nil && (result.Status == models.StatusEscaped || result.Status == models.StatusNotCovered) {
+		result = mutateConsumers(opts, &out, job, result, &mutant)
+	}
+	mutant.Status = result.Status
+	if job.acceptance != nil && (mutant.Status == models.StatusEscaped || mutant.Status == models.StatusNotCovered) {
+		mutant.Status = models.StatusAccepted
+		mutant.Acceptance = job.acceptance
+	}
+	if mutant.Status == models.StatusKilled || mutant.Status == models.StatusPanicked {
+		mutant.KilledBy = result.FailedTests
+
+		for _, test := range mutant.KilledBy {
+			console.Fverbose(&out, opts, "Killed by %s in %q", test.Name, test.Package)
+		}
+	}
+
+	console.Fdebug(&out, opts, "Finished with status %s", mutant.Status)
+
+	mutatedSourceCode, err := os.ReadFile(job.mutationFile)
+	if err != nil {
+		log.Fatal(err)
+	}
+	mutant.Mutator.MutatedSourceCode = string(mutatedSourceCode)
+
+	msg := fmt.Sprintf("%q with ID %s", job.mutationFile, job.id)
+	if mutant.Cached {
+		msg += " (cached)"
+	}
+	if mutant.Stage != "" {
+		msg += fmt.Sprintf(" in stage %s", mutant.Stage)
+	}
+	if mutant.Consumer != "" {
+		msg += fmt.Sprintf(" by consumer %q", mutant.Consumer)
+	}
+
+	switch mutant.Status {
+	case models.StatusKilled: // Tests failed - all ok
+		mutant.ProcessOutput = fmt.Sprintf("PASS %s\n", msg)
+		if !opts.Config.SilentMode {
+			console.PrintPass(&out, mutant.ProcessOutput)
+		}
+	case models.StatusPanicked: // A test panicked, which detects the mutation as well
+		mutant.ProcessOutput = fmt.Sprintf("PASS %s (test panicked)\n", msg)
+		if !opts.Config.SilentMode {
+			console.PrintPass(&out, mutant.ProcessOutput)
+		}
+	case models.StatusEscaped: // Tests passed
+		mutant.ProcessOutput = fmt.Sprintf("FAIL %s\n", msg)
+		if !opts.Config.SilentMode {
+			console.PrintFail(&out, mutant.ProcessOutput)
+		}
+	case models.StatusSkipped: // Skipped by the exec command
+		mutant.ProcessOutput = fmt.Sprintf("SKIP %s\n", msg)
+		if !opts.Config.SilentMode {
+			console.PrintSkip(&out, mutant.ProcessOutput)
+		}
+	case models.StatusBuildFailed: // Did not compile
+		mutant.ProcessOutput = fmt.Sprintf("SKIP %s (build failed)\n", msg)
+		if !opts.Config.SilentMode {
+			console.PrintSkip(&out, mutant.ProcessOutput)
+		}
+	case models.StatusNotCovered: // Not tested since no test executes the mutated code
+		mutant.ProcessOutput = fmt.Sprintf("SKIP %s (not covered)\n", msg)
+		if !opts.Config.SilentMode {
+			console.PrintSkip(&out, mutant.ProcessOutput)
+		}
+	case models.StatusAccepted: // Survived, but listed in the accepted mutations file
+		mutant.ProcessOutput = fmt.Sprintf("SKIP %s (accepted: %s)\n", msg, mutant.Acceptance.Reason)
+		if !opts.Config.SilentMode {
+			console.PrintSkip(&out, mutant.ProcessOutput)
+		}
+	case models.StatusVetFailed: // Did not pass the vet checks of go test
+		mutant.ProcessOutput = fmt.Sprintf("SKIP %s (vet failed)\n", msg)
+		if !opts.Config.SilentMode {
+			console.PrintSkip(&out, mutant.ProcessOutput)
+		}
+	case models.StatusTimedOut: // Tests did not finish in time
+		mutant.ProcessOutput = fmt.Sprintf("TIMEOUT after %s for %s\n", timeout, msg)
+		if !opts.Config.SilentMode {
+			console.PrintTimeout(&out, mutant.ProcessOutput)
+		}
+	default:
+		mutant.ProcessOutput = fmt.Sprintf("UNKOWN exit code for %s\n", msg)
+		if !opts.Config.SilentMode {
+			console.PrintUnknown(&out, mutant.ProcessOutput)
+		}
+	}
+
+	return mutantResult{
+		mutant: mutant,
+		status: result.Status,
+		output: out.Bytes(),
+	}
+}
+
+// mutateExec tests the mutation of the given job and kills the tests if they run longer than the timeout (zero means no timeout).
+func mutateExec(
+	opts *models.Options,
+	out io.Writer,
+	job mutantJob,
+	timeout time.Duration,
+	execs []string,
+	swaps *journal.Journal,
+	mutant *models.Mutant,
+) runner.TestResult {
+	file := job.originalFile
+	mutationFile := job.mutationFile
+
+	if len(execs) == 0 {
+		console.Fdebug(out, opts, "Execute built-in exec command for mutation")
+
+		diff := mutationDiff(file, mutationFile)
+
+		startLine := parser.FindOriginalStartLine(diff)
+		mutant.Mutator.OriginalStartLine = startLine
+
+		if len(job.tests) > 0 {
+			console.Fdebug(out, opts, "Run %d tests which cover the mutation", len(job.tests))
+		}
+
+		var goTest runner.GoTest
+		if job.schemaBinary != "" {
+			console.Fdebug(out, opts, "Run schemata test binary %q with mutation %s", job.schemaBinary, job.schemaID)
+
+			goTest = schemataTest(opts, job, timeout)
+		} else {
+			// The mutation is handed to the go tool as an overlay so the original source file is never touched
+			overlayFile := mutationFile + ".overlay.json"
+			err := runner.WriteOverlay(overlayFile, file, mutationFile)
+			if err != nil {
+				panic(err)
+			}
+
+			pkgName := job.pkg.Path()
+			if opts.Test.Recursive {
+				pkgName += "/..."
+			}
+
+			goTest = runner.GoTest{
+				Packages:     append([]string{pkgName}, job.dependents...),
+				Overlay:      overlayFile,
+				Flags:        job.testFlags,
+				FailFast:     !opts.Test.KillMatrix,
+				CoverPackage: job.coverPackage,
+				CoverProfile: job.coverProfile,
+				Timeout:      timeout,
+			}
+			if len(job.tests) > 0 {
+				goTest.RunPattern = testsPattern(job.tests)
+				goTest.Packages = testsPackages(job.tests)
+			}
+		}
+
+		result, goTestExitCode, err := goTest.Run()
+		if err != nil {
 			panic(err)
 		}
 
 		if opts.General.Debug {
-			fmt.Printf("%s\n", test)
+			_, _ = fmt.Fprintf(out, "%s\n", result.Output)
 		}
 
 		mutant.Diff = string(diff)
 
-		switch execExitCode {
-		case 0: // Tests passed -> FAIL
+		switch result.Status {
+		case models.StatusEscaped: // Tests passed -> FAIL
 			if !opts.Config.SilentMode {
-				console.PrintDiff(diff)
+				console.PrintDiff(out, diff)
+			}
+		case models.StatusKilled, models.StatusPanicked: // Tests failed -> PASS
+			if opts.General.Debug {
+				console.PrintDiff(out, diff)
+			}
+		case models.StatusBuildFailed: // Did not compile -> SKIP
+			if opts.General.Verbose {
+				_, _ = fmt.Fprintln(out, "Mutation did not compile")
 			}
 
-			execExitCode = 1
-		case 1: // Tests failed -> PASS
 			if opts.General.Debug {
-				console.PrintDiff(diff)
+				console.PrintDiff(out, diff)
+			}
+		case models.StatusVetFailed: // Did not pass vet -> SKIP
+			if opts.General.Verbose {
+				_, _ = fmt.Fprintln(out, "Mutation did not pass vet")
 			}
 
-			execExitCode = 0
-		case 2: // Did not compile -> SKIP
+			if opts.General.Debug {
+				console.PrintDiff(out, diff)
+			}
+		case models.StatusTimedOut: // Tests did not finish in time -> TIMEOUT
 			if opts.General.Verbose {
-				fmt.Println("Mutation did not compile")
+				_, _ = fmt.Fprintln(out, "Mutation timed out")
 			}
 
 			if opts.General.Debug {
-				console.PrintDiff(diff)
+				console.PrintDiff(out, diff)
 			}
-		default: // Unknown exit code -> SKIP
+		default: // go test did not run -> UNKNOWN
 			if !opts.Config.SilentMode {
-				fmt.Println("Unknown exit code")
-				console.PrintDiff(diff)
+				_, _ = fmt.Fprintf(out, "go test exited with %d without running the tests\n", goTestExitCode)
+				console.PrintDiff(out, diff)
 			}
 		}
 
-		return execExitCode
+		return result
 	}
 
-	console.Debug(opts, "Execute %q for mutation", opts.Exec.Exec)
+	console.Fdebug(out, opts, "Execute %q for mutation", opts.Exec.Exec)
 
 	execCommand := exec.Command(execs[0], execs[1:]...)
 
-	execCommand.Stderr = os.Stderr
-	execCommand.Stdout = os.Stdout
+	execCommand.Stderr = out
+	execCommand.Stdout = out
 
 	execCommand.Env = append(os.Environ(), []string{
 		"MUTATE_CHANGED=" + mutationFile,
 		fmt.Sprintf("MUTATE_DEBUG=%t", opts.General.Debug),
 		"MUTATE_ORIGINAL=" + file,
-		"MUTATE_PACKAGE=" + pkg.Path(),
-		fmt.Sprintf("MUTATE_TIMEOUT=%d", opts.Exec.Timeout),
+		"MUTATE_PACKAGE=" + job.pkg.Path(),
+		fmt.Sprintf("MUTATE_TIMEOUT=%d", int(math.Ceil(timeout.Seconds()))),
 		fmt.Sprintf("MUTATE_VERBOSE=%t", opts.General.Verbose),
 	}...)
 	if opts.Test.Recursive {
 		execCommand.Env = append(execCommand.Env, "TEST_RECURSIVE=true")
 	}
 
-	err := execCommand.Start()
+	// Exec commands displace the original file while they test the mutation, which is recorded in case we get interrupted
+	swap, err := swaps.Begin(file)
 	if err != nil {
 		panic(err)
 	}
+	defer func() {
+		err := swaps.End(swap)
+		if err != nil {
+			_, _ = fmt.Fprintf(out, "Could not restore %q: %v\n", file, err)
+		}
+	}()
 
-	// TODO timeout here
+	execExitCode, err := runner.Execute(execCommand, timeout)
+	if err != nil {
+		panic(err)
+	}
 
-	err = execCommand.Wait()
+	console.Fdebug(out, opts, "Exited with %d", execExitCode)
 
-	if err == nil {
-		execExitCode = 0
-	} else if e, ok := err.(*exec.ExitError); ok {
-		execExitCode = e.Sys().(syscall.WaitStatus).ExitStatus()
-	} else {
+	// Exec commands only tell the status of the mutation by their exit code
+	return runner.TestResult{
+		Status: runner.StatusOfExitCode(execExitCode),
+	}
+}
+
+// checkGates prints the violated gates of the given report, the limits of the arguments take precedence over the ones of the config file.
+func checkGates(report models.Report, gates models.Gates, args models.GateOptions) int {
+	if args.MinMsi != nil {
+		gates.MinMsi = args.MinMsi
+	}
+	if args.MaxEscaped != nil {
+		gates.MaxEscaped = args.MaxEscaped
+	}
+
+	if !gate.Enabled(gates) {
+		return returnOk
+	}
+
+	violations := gate.Check(report, gates)
+	if len(violations) == 0 {
+		fmt.Println("All quality gates passed")
+
+		return returnOk
+	}
+
+	fmt.Printf("%d quality gates failed:\n", len(violations))
+	for _, v := range violations {
+		fmt.Printf("  %s\n", v)
+	}
+
+	return returnGateFailed
+}
+
+// summaryDetails lists the counts of all categories which are not part of the mutation score line.
+func summaryDetails(report *models.Report) []string {
+	var details []string
+
+	if report.Stats.NotCoveredCount > 0 {
+		details = append(details, fmt.Sprintf("%d not covered", report.Stats.NotCoveredCount))
+	}
+	if report.Stats.PanickedCount > 0 {
+		details = append(details, fmt.Sprintf("%d panicked", report.Stats.PanickedCount))
+	}
+	if report.Stats.TimeOutCount > 0 {
+		details = append(details, fmt.Sprintf("%d timed out", report.Stats.TimeOutCount))
+	}
+
+	return details
+}
+
+// mutationDiff returns the unified diff between the original file and its mutation.
+func mutationDiff(file string, mutationFile string) []byte {
+	diff, err := exec.Command("diff", "--label=Original", "--label=New", "-u", file, mutationFile).CombinedOutput()
+
+	diffExitCode := 0
+	if e, ok := err.(*exec.ExitError); ok {
+		diffExitCode = e.Sys().(syscall.WaitStatus).ExitStatus()
+	} else if err != nil {
 		panic(err)
 	}
+	if diffExitCode != 0 && diffExitCode != 1 {
+		fmt.Printf("%s\n", diff)
+
+		panic("Could not execute diff on mutation file")
+	}
 
-	return execExitCode
+	return diff
+}
+
+// printKillMatrix prints the redundant tests and the minimal test set of the kill matrix.
+func printKillMatrix(matrix *models.KillMatrix) {
+	for _, redundant := range matrix.RedundantTests {
+		fmt.Printf("Test %s of %q is redundant, its mutants are all killed by %s of %q\n",
+			redundant.Test.Name, redundant.Test.Package, redundant.SubsumedBy.Name, redundant.SubsumedBy.Package)
+	}
+
+	names := make([]string, 0, len(matrix.MinimalTests))
+	for _, test := range matrix.MinimalTests {
+		names = append(names, test.Name)
+	}
+	fmt.Printf("%d of %d tests kill the same mutants: %s\n", len(matrix.MinimalTests), len(matrix.Tests), strings.Join(names, ", "))
+}
+
+// summaryExcluded lists the counts of all categories which are not part of the total.
+func summaryExcluded(report *models.Report) []string {
+	var excluded []string
+
+	if report.Stats.BuildFailedCount > 0 {
+		excluded = append(excluded, fmt.Sprintf("%d did not build", report.Stats.BuildFailedCount))
+	}
+	if report.Stats.VetFailedCount > 0 {
+		excluded = append(excluded, fmt.Sprintf("%d did not pass vet", report.Stats.VetFailedCount))
+	}
+	if report.Stats.AcceptedCount > 0 {
+		excluded = append(excluded, fmt.Sprintf("%d accepted", report.Stats.AcceptedCount))
+	}
+
+	return excluded
 }
 
 func main() {
 	os.Exit(mainCmd(os.Args[1:]))
 }
 
-func saveAST(mutationBlackList map[string]struct{}, file string, fset *token.FileSet, node ast.Node) (string, bool, error) {
+// checkInterruptedRuns refuses to mutate as long as an interrupted run left an original source file displaced.
+func checkInterruptedRuns(files []string) error {
+	journals, err := journal.Abandoned(os.TempDir())
+	if err != nil {
+		return err
+	}
+
+	for _, path := range journals {
+		entries, _, err := journal.Load(path)
+		if err != nil {
+			return err
+		}
+
+		displaced := 0
+		for _, e := range entries {
+			if journal.IsDisplaced(e) {
+				displaced++
+			}
+		}
+		if displaced > 0 {
+			return fmt.Errorf("An interrupted run left %d original source files displaced (see %q), put them back with \"go-mutesting restore\"", displaced, path)
+		}
+
+		// Nothing was in flight when the run got interrupted
+		_ = os.Remove(path)
+	}
+
+	for _, file := range files {
+		if _, err := os.Stat(file + journal.DisplacedSuffix); err == nil {
+			return fmt.Errorf("Found %q which looks like an original source file displaced by an interrupted run, move it back to %q or remove it", file+journal.DisplacedSuffix, file)
+		}
+	}
+
+	return nil
+}
+
+// restoreOnSignal restores all displaced files of the journal if the process is interrupted.
+func restoreOnSignal(swaps *journal.Journal) (stop func()) {
+	signals := make(chan os.Signal, 1)
+	done := make(chan struct{})
+
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+
+	go func() {
+		select {
+		case <-signals:
+			if err := swaps.RestoreAll(); err != nil {
+				_, _ = fmt.Fprintln(os.Stderr, err)
+			}
+
+			os.Exit(returnError)
+		case <-done:
+		}
+	}()
+
+	return func() {
+		signal.Stop(signals)
+		close(done)
+	}
+}
+
+func saveAST(checksums map[string]struct{}, file string, fset *token.FileSet, node ast.Node) (string, bool, error) {
 	var buf bytes.Buffer
 
 	h := md5.New()
@@ -568,11 +1549,11 @@ func saveAST(mutationBlackList map[string]struct{}, file string, fset *token.Fil
 
 	checksum := fmt.Sprintf("%x", h.Sum(nil))
 
-	if _, ok := mutationBlackList[checksum]; ok {
+	if _, ok := checksums[checksum]; ok {
 		return checksum, true, nil
 	}
 
-	mutationBlackList[checksum] = struct{}{}
+	checksums[checksum] = struct{}{}
 
 	src, err := format.Source(buf.Bytes())
 	if err != nil {
@@ -4,11 +4,27 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"go/ast"
+	goparser "go/parser"
+	"go/token"
+	"go/types"
 	"io"
+	"net/http/httptest"
 	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
 	"testing"
 
+	"github.com/avito-tech/go-mutesting"
+	"github.com/avito-tech/go-mutesting/internal/cache"
+	"github.com/avito-tech/go-mutesting/internal/filter"
+	"github.com/avito-tech/go-mutesting/internal/journal"
 	"github.com/avito-tech/go-mutesting/internal/models"
+	"github.com/avito-tech/go-mutesting/internal/sampling"
+	"github.com/avito-tech/go-mutesting/internal/schemata"
+	"github.com/avito-tech/go-mutesting/mutator"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -17,9 +33,9 @@ func TestMainSimple(t *testing.T) {
 	testMain(
 		t,
 		"../../example",
-		[]string{"--debug", "--exec-timeout", "1"},
+		[]string{"--debug"},
 		returnOk,
-		"The mutation score is 0.564516 (35 passed, 27 failed, 8 duplicated, 0 skipped, total is 62)",
+		"The mutation score is 0.564516 (35 passed, 14 failed, 8 duplicated, 0 skipped, total is 62)\nOf the total, 13 not covered\nThe covered code mutation score is 0.714286, the mutation code coverage is 0.790323",
 	)
 }
 
@@ -27,9 +43,9 @@ func TestMainRecursive(t *testing.T) {
 	testMain(
 		t,
 		"../../example",
-		[]string{"--debug", "--exec-timeout", "1", "./..."},
+		[]string{"--debug", "./..."},
 		returnOk,
-		"The mutation score is 0.590909 (39 passed, 27 failed, 8 duplicated, 0 skipped, total is 66)",
+		"The mutation score is 0.590909 (39 passed, 14 failed, 8 duplicated, 0 skipped, total is 66)\nOf the total, 13 not covered\nThe covered code mutation score is 0.735849, the mutation code coverage is 0.803030",
 	)
 }
 
@@ -37,9 +53,9 @@ func TestMainFromOtherDirectory(t *testing.T) {
 	testMain(
 		t,
 		"../..",
-		[]string{"--debug", "--exec-timeout", "1", "github.com/avito-tech/go-mutesting/example"},
+		[]string{"--debug", "github.com/avito-tech/go-mutesting/example"},
 		returnOk,
-		"The mutation score is 0.564516 (35 passed, 27 failed, 8 duplicated, 0 skipped, total is 62)",
+		"The mutation score is 0.564516 (35 passed, 14 failed, 8 duplicated, 0 skipped, total is 62)\nOf the total, 13 not covered\nThe covered code mutation score is 0.714286, the mutation code coverage is 0.790323",
 	)
 }
 
@@ -47,7 +63,7 @@ func TestMainMatch(t *testing.T) {
 	testMain(
 		t,
 		"../../example",
-		[]string{"--debug", "--exec", "../scripts/exec/test-mutated-package.sh", "--exec-timeout", "1", "--match", "baz", "./..."},
+		[]string{"--debug", "--exec", "../scripts/exec/test-mutated-package.sh", "--match", "baz", "./..."},
 		returnOk,
 		"The mutation score is 0.500000 (4 passed, 4 failed, 0 duplicated, 0 skipped, total is 8)",
 	)
@@ -57,9 +73,952 @@ func TestMainSkipWithoutTest(t *testing.T) {
 	testMain(
 		t,
 		"../../example",
-		[]string{"--debug", "--exec-timeout", "1", "--config", "../testdata/configs/configSkipWithoutTest.yml.test"},
+		[]string{"--debug", "--config", "../testdata/configs/configSkipWithoutTest.yml.test"},
 		returnOk,
-		"The mutation score is 0.583333 (35 passed, 25 failed, 8 duplicated, 0 skipped, total is 60)",
+		"The mutation score is 0.583333 (35 passed, 14 failed, 8 duplicated, 0 skipped, total is 60)\nOf the total, 11 not covered\nThe covered code mutation score is 0.714286, the mutation code coverage is 0.816667",
+	)
+}
+
+func TestMainJobs(t *testing.T) {
+	testMain(
+		t,
+		"../../example",
+		[]string{"--debug", "--jobs", "4", "--config", "../testdata/configs/configSkipWithoutTest.yml.test", "--match", "baz", "example.go", "sub/sub.go"},
+		returnOk,
+		"The mutation score is 0.500000 (4 passed, 0 failed, 0 duplicated, 0 skipped, total is 8)\nOf the total, 4 not covered\nThe covered code mutation score is 1.000000, the mutation code coverage is 0.500000",
+	)
+}
+
+func TestMainCoverProfile(t *testing.T) {
+	testMain(
+		t,
+		"../../example",
+		[]string{"--exec", "../scripts/exec/test-mutated-package.sh", "--coverprofile", "../testdata/coverage/baz.cover", "--match", "baz", "example.go", "sub/sub.go"},
+		returnOk,
+		"The mutation score is 0.500000 (4 passed, 0 failed, 0 duplicated, 0 skipped, total is 8)\nOf the total, 4 not covered\nThe covered code mutation score is 1.000000, the mutation code coverage is 0.500000",
+	)
+}
+
+func TestMainTimeout(t *testing.T) {
+	testMain(
+		t,
+		"../../example",
+		[]string{"--debug", "--exec", "sleep 30", "--exec-timeout", "1", "--no-baseline", "--match", "baz", "example.go", "sub/sub.go"},
+		returnOk,
+		"The mutation score is 1.000000 (0 passed, 0 failed, 0 duplicated, 0 skipped, total is 8)\nOf the total, 8 timed out",
+	)
+}
+
+func TestMainGates(t *testing.T) {
+	testMain(
+		t,
+		"../../example",
+		[]string{"--exec", "../scripts/exec/test-mutated-package.sh", "--match", "baz", "--min-msi", "0.5", "example.go", "sub/sub.go"},
+		returnOk,
+		"All quality gates passed",
+	)
+	testMain(
+		t,
+		"../../example",
+		[]string{"--exec", "../scripts/exec/test-mutated-package.sh", "--match", "baz", "--min-msi", "0.75", "--max-escaped", "1", "example.go", "sub/sub.go"},
+		returnGateFailed,
+		"2 quality gates failed:\n"+
+			"  The mutation score of the total is 0.500000, 0.250000 below the minimum of 0.750000\n"+
+			"  The count of escaped mutations of the total is 4, 3 above the maximum of 1\n",
+	)
+	testMain(
+		t,
+		"../../example",
+		[]string{"--exec", "../scripts/exec/test-mutated-package.sh", "--match", "baz", "--config", "../testdata/configs/configGates.yml.test", "example.go", "sub/sub.go"},
+		returnGateFailed,
+		"1 quality gates failed:\n"+
+			"  The count of escaped mutations of package \"github.com/avito-tech/go-mutesting/example/...\" is 4, 2 above the maximum of 2\n",
+	)
+}
+
+func TestMainKillMatrix(t *testing.T) {
+	saveReportFileName := models.ReportFileName
+	defer func() {
+		models.ReportFileName = saveReportFileName
+	}()
+	models.ReportFileName = filepath.Join(t.TempDir(), "report.json")
+
+	out := testMain(
+		t,
+		"../../testdata/killmatrix",
+		[]string{"--kill-matrix", "./..."},
+		returnOk,
+		`Test TestAddZero of "example.com/killmatrix" is redundant, its mutants are all killed by TestAdd of "example.com/killmatrix"`,
+	)
+	assert.Contains(t, out, "2 of 3 tests kill the same mutants: TestAdd, TestSub")
+
+	content, err := os.ReadFile(models.ReportFileName)
+	assert.NoError(t, err)
+	var report models.Report
+	assert.NoError(t, json.Unmarshal(content, &report))
+	if !assert.NotNil(t, report.KillMatrix) {
+		return
+	}
+
+	add := models.Test{Package: "example.com/killmatrix", Name: "TestAdd"}
+	addZero := models.Test{Package: "example.com/killmatrix", Name: "TestAddZero"}
+	sub := models.Test{Package: "example.com/killmatrix", Name: "TestSub"}
+	assert.Equal(t, []models.Test{add, addZero, sub}, report.KillMatrix.Tests)
+
+	var rows []models.KillMatrixRow
+	for _, row := range report.KillMatrix.Mutants {
+		assert.NotEmpty(t, row.ID)
+		assert.NotEmpty(t, row.Checksum)
+		row.ID, row.Checksum = "", ""
+		rows = append(rows, row)
+	}
+	assert.Equal(t, []models.KillMatrixRow{
+		{MutatorName: "arithmetic/base", OriginalFilePath: "killmatrix.go", OriginalStartLine: 5, KilledBy: []int{0, 1}},
+		{MutatorName: "arithmetic/base", OriginalFilePath: "killmatrix.go", OriginalStartLine: 10, KilledBy: []int{2}},
+	}, rows)
+	assert.Equal(t, []models.RedundantTest{{Test: addZero, SubsumedBy: add}}, report.KillMatrix.RedundantTests)
+	assert.Equal(t, []models.Test{add, sub}, report.KillMatrix.MinimalTests)
+}
+
+func TestMainSelectTests(t *testing.T) {
+	testMain(
+		t,
+		"../../example",
+		[]string{"--select-tests", "--match", "baz", "example.go", "sub/sub.go"},
+		returnOk,
+		"The mutation score is 0.500000 (4 passed, 0 failed, 0 duplicated, 0 skipped, total is 8)\nOf the total, 4 not covered",
+	)
+}
+
+func TestMainSchemata(t *testing.T) {
+	testMain(
+		t,
+		"../../example",
+		[]string{"--schemata", "--match", "baz", "example.go", "sub/sub.go"},
+		returnOk,
+		"The mutation score is 0.500000 (4 passed, 0 failed, 0 duplicated, 0 skipped, total is 8)\nOf the total, 4 not covered\nThe covered code mutation score is 1.000000, the mutation code coverage is 0.500000",
+	)
+}
+
+func TestMainStages(t *testing.T) {
+	saveReportFileName := models.ReportFileName
+	defer func() {
+		models.ReportFileName = saveReportFileName
+	}()
+	models.ReportFileName = filepath.Join(t.TempDir(), "report.json")
+
+	// Only the integration tests test negative numbers
+	testMain(
+		t,
+		"../../testdata/stages",
+		[]string{"--config", "../configs/configStages.yml.test", "."},
+		returnOk,
+		"The mutation score is 0.500000 (2 passed, 2 failed, 0 duplicated, 0 skipped, total is 4)",
+	)
+
+	content, err := os.ReadFile(models.ReportFileName)
+	assert.NoError(t, err)
+
+	var report models.Report
+	assert.NoError(t, json.Unmarshal(content, &report))
+	assert.Len(t, report.Killed, 2)
+	for _, mutant := range append(report.Killed, report.Escaped...) {
+		assert.Equal(t, "integration", mutant.Stage)
+	}
+
+	testMain(
+		t,
+		"../../testdata/stages",
+		[]string{"--exec", "../../scripts/exec/test-mutated-package.sh", "--config", "../configs/configStages.yml.test", "."},
+		returnError,
+		`flags and tags of test stage "integration" need the built-in exec command`,
+	)
+
+	// A stage which fails on the original code would kill every mutation which survives the stages before
+	testMain(
+		t,
+		"../../testdata/stages",
+		[]string{"--config", "../configs/configStagesBroken.yml.test", "."},
+		returnError,
+		"do not pass on the original code in stage broken (killed)",
+	)
+	testMain(
+		t,
+		"../../testdata/stages",
+		[]string{"--config", "../configs/configStagesBroken.yml.test", "--exclude-failing-packages", "."},
+		returnOk,
+		"The mutation score is 0.000000 (0 passed, 0 failed, 0 duplicated, 0 skipped, total is 0)",
+	)
+}
+
+func TestMainTestScope(t *testing.T) {
+	saveReportFileName := models.ReportFileName
+	defer func() {
+		models.ReportFileName = saveReportFileName
+	}()
+	models.ReportFileName = filepath.Join(t.TempDir(), "report.json")
+
+	testMain(
+		t,
+		"../../testdata/scope",
+		[]string{"./lib"},
+		returnOk,
+		"The mutation score is 0.000000 (0 passed, 1 failed, 0 duplicated, 0 skipped, total is 2)\nOf the total, 1 not covered",
+	)
+
+	// Only the tests of the service package test Max thoroughly
+	testMain(
+		t,
+		"../../testdata/scope",
+		[]string{"--test-scope", "dependents", "--verbose", "./lib"},
+		returnOk,
+		"The mutation score is 0.500000 (1 passed, 1 failed, 0 duplicated, 0 skipped, total is 2)",
+	)
+
+	testMain(
+		t,
+		"../../testdata/scope",
+		[]string{"--test-scope", "dependents", "--test-scope-depth", "1", "--verbose", "./lib"},
+		returnOk,
+		`Run the tests of 1 packages which depend on "example.com/scope/lib" as well`,
+	)
+
+	testMain(
+		t,
+		"../../testdata/scope",
+		[]string{"--test-scope", "dependents", "--select-tests", "./lib"},
+		returnError,
+		"--test-scope dependents cannot be combined with --select-tests",
+	)
+
+	// The dependents are those of the module of the mutated package, not of the module of the current directory
+	scope, err := filepath.Abs("../../testdata/scope")
+	assert.NoError(t, err)
+	dir := t.TempDir()
+	assert.NoError(t, os.Mkdir(filepath.Join(dir, "other"), 0755))
+	writeTestFile(t, filepath.Join(dir, "go.work"), "go 1.21\n\nuse (\n\t./other\n\t"+scope+"\n)\n")
+	writeTestFile(t, filepath.Join(dir, "other", "go.mod"), "module example.com/other\n\ngo 1.21\n")
+	writeTestFile(t, filepath.Join(dir, "other", "other.go"), "package other\n")
+	// Workspaces do not allow -mod=mod
+	t.Setenv("GOFLAGS", "")
+
+	testMain(
+		t,
+		filepath.Join(dir, "other"),
+		[]string{"--test-scope", "dependents", filepath.Join(scope, "lib")},
+		returnOk,
+		"The mutation score is 0.500000 (1 passed, 1 failed, 0 duplicated, 0 skipped, total is 2)",
+	)
+}
+
+func TestMainConsumers(t *testing.T) {
+	saveReportFileName := models.ReportFileName
+	defer func() {
+		models.ReportFileName = saveReportFileName
+	}()
+	models.ReportFileName = filepath.Join(t.TempDir(), "report.json")
+
+	// The consumer module requires the library without a replace directive, so its tests can only run against the local library
+	testMain(
+		t,
+		"../../testdata/scope",
+		[]string{"--consumer", "../consumer", "./lib"},
+		returnOk,
+		"The mutation score is 0.500000 (1 passed, 1 failed, 0 duplicated, 0 skipped, total is 2)",
+	)
+
+	report, err := os.ReadFile(models.ReportFileName)
+	assert.NoError(t, err)
+	assert.Contains(t, string(report), `"consumer":"`)
+
+	testMain(
+		t,
+		"../../testdata/scope",
+		[]string{"--consumer", "../consumer", "--exec", "true", "./lib"},
+		returnError,
+		"--consumer needs the built-in exec command",
+	)
+
+	testMain(
+		t,
+		"../../testdata/scope",
+		[]string{"--consumer", "../consumer", "--select-tests", "./lib"},
+		returnError,
+		"--consumer cannot be combined with --select-tests",
+	)
+
+	// The consumer runs its tests with the tags of every stage as well
+	testMain(
+		t,
+		"../../testdata/scope",
+		[]string{"--consumer", "../consumer", "--config", "../configs/configStagesBroken.yml.test", "./lib"},
+		returnError,
+		"do not pass on the original code of the library in stage broken",
+	)
+}
+
+func TestBrokenMutations(t *testing.T) {
+	regions := map[string][]schemata.Region{
+		"/pkg/a.go": {
+			{StartLine: 5, EndLine: 7, IDs: []string{"a.go.0"}},
+			{StartLine: 8, EndLine: 9, IDs: []string{"a.go.1"}},
+			{StartLine: 5, EndLine: 12, IDs: []string{"a.go.0", "a.go.1"}},
+		},
+	}
+
+	output := []byte("# example [example.test]\n" +
+		"./a.go:6:2: declared and not used: x\n" +
+		"/pkg/a.go:12:1: missing return\n" +
+		"./a.go:2:1: not part of a mutation\n" +
+		"./a_test.go:8:3: undefined: y\n")
+
+	assert.Equal(t, []string{"a.go.0", "a.go.0", "a.go.1"}, brokenMutations(output, "/pkg", regions))
+}
+
+func TestMainCache(t *testing.T) {
+	cacheDir := t.TempDir()
+
+	testMain(
+		t,
+		"../../example",
+		[]string{"--cache-dir", cacheDir, "--match", "baz", "example.go", "sub/sub.go"},
+		returnOk,
+		"The mutation score is 0.500000 (4 passed, 0 failed, 0 duplicated, 0 skipped, total is 8)\nOf the total, 4 not covered\nThe covered code mutation score is 1.000000, the mutation code coverage is 0.500000\n",
+	)
+	testMain(
+		t,
+		"../../example",
+		[]string{"--cache-dir", cacheDir, "--match", "baz", "example.go", "sub/sub.go"},
+		returnOk,
+		"The mutation score is 0.500000 (4 passed, 0 failed, 0 duplicated, 0 skipped, total is 8)\nOf the total, 4 not covered\nThe covered code mutation score is 1.000000, the mutation code coverage is 0.500000\n4 results were reused from the cache",
+	)
+}
+
+func TestMainCacheURL(t *testing.T) {
+	remote, err := cache.Open(t.TempDir())
+	assert.Nil(t, err)
+
+	server := httptest.NewServer(cache.Handler(remote))
+	defer server.Close()
+
+	// Results which are shared by one run are reused by another run without a local cache
+	testMain(
+		t,
+		"../../example",
+		[]string{"--cache-dir", t.TempDir(), "--cache-url", server.URL, "--match", "baz", "example.go", "sub/sub.go"},
+		returnOk,
+		"The mutation score is 0.500000 (4 passed, 0 failed, 0 duplicated, 0 skipped, total is 8)",
+	)
+	testMain(
+		t,
+		"../../example",
+		[]string{"--cache-url", server.URL, "--match", "baz", "example.go", "sub/sub.go"},
+		returnOk,
+		"The mutation score is 0.500000 (4 passed, 0 failed, 0 duplicated, 0 skipped, total is 8)\nOf the total, 4 not covered\nThe covered code mutation score is 1.000000, the mutation code coverage is 0.500000\n4 results were reused from the cache",
+	)
+}
+
+func TestTestsPattern(t *testing.T) {
+	tests := []models.Test{
+		{Package: "example", Name: "TestFoo"},
+		{Package: "example/sub", Name: "TestBar"},
+		{Package: "example/other", Name: "TestFoo"},
+	}
+
+	assert.Equal(t, "^(TestFoo|TestBar)$", testsPattern(tests))
+	assert.Equal(t, []string{"example", "example/sub", "example/other"}, testsPackages(tests))
+}
+
+func TestMainResume(t *testing.T) {
+	checkpointFile := filepath.Join(t.TempDir(), "checkpoint.jsonl")
+
+	testMain(
+		t,
+		"../../example",
+		[]string{"--checkpoint", checkpointFile, "--match", "baz", "example.go", "sub/sub.go"},
+		returnOk,
+		"The mutation score is 0.500000 (4 passed, 0 failed, 0 duplicated, 0 skipped, total is 8)",
+	)
+
+	// Interrupt the run while it writes the 6th result
+	content, err := os.ReadFile(checkpointFile)
+	assert.NoError(t, err)
+	lines := bytes.SplitAfter(content, []byte("\n"))
+	assert.Len(t, lines, 9)
+	interrupted := append(bytes.Join(lines[:5], nil), lines[5][:len(lines[5])/2]...)
+	assert.NoError(t, os.WriteFile(checkpointFile, interrupted, 0666))
+
+	testMain(
+		t,
+		"../../example",
+		[]string{"--resume", checkpointFile, "--match", "baz", "example.go", "sub/sub.go"},
+		returnOk,
+		"The mutation score is 0.500000 (4 passed, 0 failed, 0 duplicated, 0 skipped, total is 8)\nOf the total, 4 not covered\nThe covered code mutation score is 1.000000, the mutation code coverage is 0.500000",
+	)
+
+	content, err = os.ReadFile(checkpointFile)
+	assert.NoError(t, err)
+	assert.Len(t, bytes.SplitAfter(content, []byte("\n")), 9)
+
+	testMain(
+		t,
+		"../../example",
+		[]string{"--resume", checkpointFile, "--match", "baz", "example.go", "sub/sub.go"},
+		returnOk,
+		"Resume 8 of 8 mutations from checkpoint",
+	)
+}
+
+func TestParseShard(t *testing.T) {
+	k, n, err := parseShard("2/3")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, k)
+	assert.Equal(t, 3, n)
+
+	for _, shard := range []string{"2", "a/3", "0/3", "4/3", "1/0"} {
+		_, _, err := parseShard(shard)
+		assert.Error(t, err, shard)
+	}
+}
+
+func TestShardJobs(t *testing.T) {
+	var jobs []mutantJob
+	for i := 0; i < 100; i++ {
+		jobs = append(jobs, mutantJob{id: fmt.Sprintf("%032d", i)})
+	}
+
+	// Every mutation is part of exactly one shard
+	seen := make(map[string]int)
+	for k := 1; k <= 3; k++ {
+		shard := shardJobs(jobs, k, 3)
+		assert.NotEmpty(t, shard)
+		assert.Equal(t, shard, shardJobs(jobs, k, 3))
+
+		for _, job := range shard {
+			seen[job.id]++
+		}
+	}
+	assert.Len(t, seen, len(jobs))
+	for _, count := range seen {
+		assert.Equal(t, 1, count)
+	}
+}
+
+func TestSampleJobs(t *testing.T) {
+	var jobs []mutantJob
+	for i := 0; i < 100; i++ {
+		jobs = append(jobs, mutantJob{id: fmt.Sprintf("%032d", i)})
+	}
+
+	sample := sampleJobs(jobs, 10, 1)
+	assert.Len(t, sample, 10)
+	assert.Equal(t, sample, sampleJobs(jobs, 10, 1))
+	assert.NotEqual(t, sample, sampleJobs(jobs, 10, 2))
+
+	// The original order is kept
+	for i := 1; i < len(sample); i++ {
+		assert.Less(t, sample[i-1].id, sample[i].id)
+	}
+}
+
+func TestPrioritizeJobs(t *testing.T) {
+	var jobs []mutantJob
+	for i := 0; i < 20; i++ {
+		jobs = append(jobs, mutantJob{id: fmt.Sprintf("%032d", i)})
+	}
+	jobs[5].onChangedLines = true
+	jobs[10].cached = &cache.Entry{Status: models.StatusKilled}
+
+	ordered := prioritizeJobs(jobs, 1)
+	assert.ElementsMatch(t, jobs, ordered)
+	assert.Equal(t, jobs[10].id, ordered[0].id)
+	assert.Equal(t, jobs[5].id, ordered[1].id)
+	assert.Equal(t, ordered, prioritizeJobs(jobs, 1))
+
+	// All others keep the random order of the seed
+	keys := make([]string, len(jobs))
+	for i, job := range jobs {
+		keys[i] = job.id
+	}
+	var others []string
+	for _, i := range sampling.Order(keys, 1) {
+		if !prioritized(jobs[i]) {
+			others = append(others, jobs[i].id)
+		}
+	}
+	for i, id := range others {
+		assert.Equal(t, id, ordered[i+2].id)
+	}
+}
+
+func TestEstimateMsi(t *testing.T) {
+	// The escaped prioritized mutations are not part of the random results
+	random := &models.Report{}
+	for i := 0; i < 6; i++ {
+		random.Add(models.Mutant{Status: models.StatusKilled})
+	}
+
+	s := &models.Sampling{Population: 10, Sampled: 10, Prioritized: 4}
+	estimateMsi(s, random)
+	assert.Equal(t, 1.0, s.MsiLow)
+	assert.Equal(t, 1.0, s.MsiHigh)
+
+	s = &models.Sampling{Population: 10, Sampled: 10, Prioritized: 4, OverBudget: 3}
+	random = &models.Report{}
+	for i := 0; i < 3; i++ {
+		random.Add(models.Mutant{Status: models.StatusKilled})
+	}
+	estimateMsi(s, random)
+	assert.Less(t, s.MsiLow, 1.0)
+	assert.Equal(t, 1.0, s.MsiHigh)
+}
+
+func TestMainSample(t *testing.T) {
+	testMain(
+		t,
+		"../../example",
+		[]string{"--sample", "3", "--seed", "1", "--match", "baz", "example.go", "sub/sub.go"},
+		returnOk,
+		"Sample 3 of 8 mutations with seed 1",
+	)
+
+	out := testMain(
+		t,
+		"../../example",
+		[]string{"--time-budget", "1ns", "--seed", "1", "--match", "baz", "example.go", "sub/sub.go"},
+		returnOk,
+		"The time budget of 1ns ran out, 8 of 8 mutations were not tested",
+	)
+	assert.Contains(t, out, "0 of 8 mutations were tested (seed 1), the mutation score of all of them is estimated between 0.000000 and 1.000000 (95% confidence)")
+
+	testMain(
+		t,
+		"../../example",
+		[]string{"--sample", "1.5"},
+		returnError,
+		`sample "1.5" is neither a fraction between 0 and 1 nor a positive count`,
+	)
+}
+
+func TestMainShardMerge(t *testing.T) {
+	dir := t.TempDir()
+
+	saveReportFileName := models.ReportFileName
+	defer func() {
+		models.ReportFileName = saveReportFileName
+	}()
+
+	var reports []string
+	for _, shard := range []string{"1/2", "2/2"} {
+		models.ReportFileName = filepath.Join(dir, fmt.Sprintf("report.%d.json", len(reports)))
+		reports = append(reports, models.ReportFileName)
+
+		testMain(
+			t,
+			"../../example",
+			[]string{"--shard", shard, "--match", "baz", "example.go", "sub/sub.go"},
+			returnOk,
+			"Shard "+shard+" tests",
+		)
+	}
+
+	models.ReportFileName = filepath.Join(dir, "report.json")
+
+	// The shards together are the whole run
+	testMain(
+		t,
+		"../../example",
+		append([]string{"merge"}, reports...),
+		returnOk,
+		"Merged 2 reports, the mutation score is 0.500000 (4 passed, 0 failed, 0 skipped, total is 8)",
+	)
+
+	_, err := os.Stat(models.ReportFileName)
+	assert.NoError(t, err)
+}
+
+func TestMainBlacklist(t *testing.T) {
+	out := testMain(
+		t,
+		"../../example",
+		[]string{"--debug", "--no-exec", "--match", "baz", "example.go", "sub/sub.go"},
+		returnOk,
+		"Cannot do a mutation testing summary since no exec command was executed.",
+	)
+
+	saved := regexp.MustCompile(`with ID ([0-9a-f]{32}) and checksum ([0-9a-f]{32})`).FindStringSubmatch(out)
+	assert.Len(t, saved, 3)
+	id, checksum := saved[1], saved[2]
+
+	// Checksums of old blacklists are translated to IDs
+	blacklist := filepath.Join(t.TempDir(), "blacklist")
+	writeTestFile(t, blacklist, checksum+"\n"+strings.Repeat("0", 32)+"\n")
+
+	testMain(
+		t,
+		"../../example",
+		[]string{"--blacklist", blacklist, "--migrate-blacklist", "--match", "baz", "example.go", "sub/sub.go"},
+		returnOk,
+		id+"\n",
+	)
+
+	writeTestFile(t, blacklist, id+"\n")
+
+	testMain(
+		t,
+		"../../example",
+		[]string{"--exec", "../scripts/exec/test-mutated-package.sh", "--blacklist", blacklist, "--match", "baz", "example.go", "sub/sub.go"},
+		returnOk,
+		"total is 7)",
+	)
+}
+
+func TestMainAccepted(t *testing.T) {
+	out := testMain(
+		t,
+		"../../example",
+		[]string{"--exec", "../scripts/exec/test-mutated-package.sh", "--match", "baz", "example.go", "sub/sub.go"},
+		returnOk,
+		"The mutation score is 0.500000 (4 passed, 4 failed, 0 duplicated, 0 skipped, total is 8)",
+	)
+
+	escaped := regexp.MustCompile(`FAIL "[^"]+" with ID ([0-9a-f]{32})`).FindStringSubmatch(out)
+	assert.Len(t, escaped, 2)
+
+	accepted := filepath.Join(t.TempDir(), "accepted.yml")
+	writeTestFile(t, accepted, "mutants:\n"+
+		"  - id: "+escaped[1]+"\n    reason: equivalent mutation\n"+
+		"  - id: "+strings.Repeat("0", 32)+"\n    reason: gone\n    owner: team-a\n    expires: 2020-01-01\n")
+
+	out = testMain(
+		t,
+		"../../example",
+		[]string{"--exec", "../scripts/exec/test-mutated-package.sh", "--accepted", accepted, "--match", "baz", "example.go", "sub/sub.go"},
+		returnOk,
+		"The mutation score is 0.571429 (4 passed, 3 failed, 0 duplicated, 0 skipped, total is 7)\nNot part of the total, 1 accepted",
+	)
+	assert.Contains(t, out, "WARNING the acceptance of mutation 00000000000000000000000000000000 (owner team-a) expired on 2020-01-01, it counts against the score again")
+	assert.Contains(t, out, "(accepted: equivalent mutation)")
+}
+
+func TestMatchMutator(t *testing.T) {
+	assert.True(t, matchMutator("branch/if", "branch/if"))
+	assert.False(t, matchMutator("branch/if", "branch/else"))
+	assert.True(t, matchMutator("branch/*", "branch/else"))
+	assert.False(t, matchMutator("branch/*", "loop/break"))
+	assert.True(t, matchMutator("*", "loop/break"))
+}
+
+func TestMainAccept(t *testing.T) {
+	testMain(
+		t,
+		"../../example",
+		[]string{"--exec", "../scripts/exec/test-mutated-package.sh", "--match", "baz", "example.go", "sub/sub.go"},
+		returnOk,
+		"The mutation score is 0.500000 (4 passed, 4 failed, 0 duplicated, 0 skipped, total is 8)",
+	)
+
+	accepted := filepath.Join(t.TempDir(), "accepted.yml")
+
+	testMain(
+		t,
+		"../../example",
+		[]string{"accept", "--accepted", accepted, "--reason", "nothing matches", "--function", "^qux$"},
+		returnOk,
+		"Accepted 0 surviving mutations into",
+	)
+	assert.NoFileExists(t, accepted)
+
+	testMain(
+		t,
+		"../../example",
+		[]string{"accept", "--accepted", accepted, "--reason", "equivalent mutations", "--owner", "team-a", "--function", "^baz$", "--file", "*.go"},
+		returnOk,
+		"Accepted 4 surviving mutations into",
+	)
+	testMain(
+		t,
+		"../../example",
+		[]string{"accept", "--accepted", accepted, "--reason", "equivalent mutations"},
+		returnOk,
+		fmt.Sprintf("Accepted 0 surviving mutations into %q (4 were already accepted)", accepted),
+	)
+
+	testMain(
+		t,
+		"../../example",
+		[]string{"--exec", "../scripts/exec/test-mutated-package.sh", "--accepted", accepted, "--match", "baz", "example.go", "sub/sub.go"},
+		returnOk,
+		"The mutation score is 1.000000 (4 passed, 0 failed, 0 duplicated, 0 skipped, total is 4)\nNot part of the total, 4 accepted",
+	)
+}
+
+func TestMainAcceptNotCovered(t *testing.T) {
+	saveReportFileName := models.ReportFileName
+	defer func() {
+		models.ReportFileName = saveReportFileName
+	}()
+	models.ReportFileName = filepath.Join(t.TempDir(), "report.json")
+
+	testMain(
+		t,
+		"../../example",
+		[]string{"--match", "baz", "example.go", "sub/sub.go"},
+		returnOk,
+		"The mutation score is 0.500000 (4 passed, 0 failed, 0 duplicated, 0 skipped, total is 8)\nOf the total, 4 not covered",
+	)
+
+	accepted := filepath.Join(t.TempDir(), "accepted.yml")
+
+	testMain(
+		t,
+		"../../example",
+		[]string{"accept", "--report", models.ReportFileName, "--accepted", accepted, "--reason", "not worth a test"},
+		returnOk,
+		"Accepted 4 surviving mutations into",
+	)
+
+	testMain(
+		t,
+		"../../example",
+		[]string{"--accepted", accepted, "--match", "baz", "example.go", "sub/sub.go"},
+		returnOk,
+		"The mutation score is 1.000000 (4 passed, 0 failed, 0 duplicated, 0 skipped, total is 4)\nNot part of the total, 4 accepted",
+	)
+}
+
+func TestMainAcceptInvalid(t *testing.T) {
+	testMain(
+		t,
+		"../../example",
+		[]string{"accept", "--accepted", "accepted.yml", "--reason", "equivalent", "--expires", "tomorrow"},
+		returnError,
+		`--expires "tomorrow" is not a YYYY-MM-DD date`,
+	)
+}
+
+func TestMainRerunEscaped(t *testing.T) {
+	dir := t.TempDir()
+
+	saveReportFileName := models.ReportFileName
+	defer func() {
+		models.ReportFileName = saveReportFileName
+	}()
+	models.ReportFileName = filepath.Join(dir, "report.json")
+
+	testMain(
+		t,
+		"../../example",
+		[]string{"--exec", "../scripts/exec/test-mutated-package.sh", "--match", "baz", "example.go", "sub/sub.go"},
+		returnOk,
+		"The mutation score is 0.500000 (4 passed, 4 failed, 0 duplicated, 0 skipped, total is 8)",
+	)
+
+	content, err := os.ReadFile(models.ReportFileName)
+	assert.NoError(t, err)
+
+	var report models.Report
+	assert.NoError(t, json.Unmarshal(content, &report))
+	assert.Len(t, report.Escaped, 4)
+
+	// One escaped mutant got killed by a new test, and the code of another one changed
+	report.Escaped = append(report.Escaped, report.Killed[0])
+	report.Escaped[0].ID = strings.Repeat("0", 32)
+
+	previous := filepath.Join(dir, "previous.json")
+	content, err = json.Marshal(report)
+	assert.NoError(t, err)
+	writeTestFile(t, previous, string(content))
+
+	out := testMain(
+		t,
+		"../../example",
+		[]string{"--exec", "../scripts/exec/test-mutated-package.sh", "--rerun-escaped", previous, "--match", "baz", "example.go", "sub/sub.go"},
+		returnOk,
+		"The mutation score is 0.250000 (1 passed, 3 failed, 0 duplicated, 0 skipped, total is 4)",
+	)
+	assert.Contains(t, out, "STALE 00000000000000000000000000000000 of")
+	assert.Contains(t, out, fmt.Sprintf("Of 5 mutations which escaped in %q, 1 are killed now, 3 still escape and 1 are stale", previous))
+
+	content, err = os.ReadFile(models.ReportFileName)
+	assert.NoError(t, err)
+
+	var rerun models.Report
+	assert.NoError(t, json.Unmarshal(content, &rerun))
+	if assert.NotNil(t, rerun.Rerun) {
+		assert.Equal(t, []string{report.Killed[0].ID}, rerun.Rerun.Killed)
+		assert.Len(t, rerun.Rerun.Escaped, 3)
+		assert.Len(t, rerun.Rerun.Stale, 1)
+	}
+}
+
+func TestMutantIDs(t *testing.T) {
+	// mutantIDsOf returns the IDs of the mutations of the given mutator which the filters do not skip, in the order of the mutations
+	mutantIDsOf := func(name string, src string, mutatorName string, filters ...filter.NodeFilter) []string {
+		fset := token.NewFileSet()
+		file, err := goparser.ParseFile(fset, name, src, 0)
+		assert.NoError(t, err)
+
+		info := &types.Info{
+			Types: make(map[ast.Expr]types.TypeAndValue),
+			Defs:  make(map[*ast.Ident]types.Object),
+			Uses:  make(map[*ast.Ident]types.Object),
+		}
+		pkg, err := (&types.Config{}).Check("example.com/a", fset, []*ast.File{file}, info)
+		assert.NoError(t, err)
+
+		for _, f := range filters {
+			if c, ok := f.(filter.NodeCollector); ok {
+				c.Collect(file, fset, name)
+			}
+		}
+
+		m, err := mutator.New(mutatorName)
+		assert.NoError(t, err)
+
+		var assigned []string
+		changed, _ := mutesting.MutateWalkNodes(pkg, info, file, newMutantIDs(map[string]string{}).mutator(mutatorItem{Name: mutatorName, Mutator: m}, "example.com/a", name, file, filters, &assigned))
+		for range changed {
+			changed <- true
+		}
+
+		return assigned
+	}
+
+	before := mutantIDsOf("a.go", "package a\n\nfunc F(a []int) {\n\ta[0]++\n\ta[1]++\n\ta[0]++\n}\n", "statement/remove")
+	after := mutantIDsOf("a.go", "package a\n\n// G was added\nfunc G() {}\n\nfunc F(a []int) {\n\ta[0]++\n\ta[2]++\n\ta[0]++\n}\n", "statement/remove")
+
+	// Changing a statement only changes the ID of its own removal, although all removals are mutations of the same block
+	if assert.Len(t, before, 3) && assert.Len(t, after, 3) {
+		assert.Equal(t, before[0], after[0])
+		assert.NotEqual(t, before[1], after[1])
+		assert.Equal(t, before[2], after[2])
+
+		// Identical statements are told apart by their index
+		assert.NotEqual(t, before[0], before[2])
+	}
+
+	// The same code in another file of the package has other IDs
+	assert.NotEqual(t, before, mutantIDsOf("b.go", "package a\n\nfunc F(a []int) {\n\ta[0]++\n\ta[1]++\n\ta[0]++\n}\n", "statement/remove"))
+
+	// Mutations which the filters skip still take their index
+	src := "package a\n\nfunc F(a, b int) int {\n\tc := a + b\n\treturn c * (a + b)\n}\n"
+	all := mutantIDsOf("a.go", src, "arithmetic/base")
+	changedLine := mutantIDsOf("a.go", src, "arithmetic/base", filter.NewChangedLinesFilter(map[string][]int64{"a.go": {5}}))
+	if assert.Len(t, all, 3) && assert.Len(t, changedLine, 2) {
+		assert.Equal(t, all[1:], changedLine)
+	}
+}
+
+func TestEnclosingFunction(t *testing.T) {
+	src := "package a\n\nvar x = 1 + 2\n\ntype T[K any] struct{}\n\nfunc (t *T[K]) M() int {\n\treturn 3 + 4\n}\n"
+	file, err := goparser.ParseFile(token.NewFileSet(), "a.go", src, 0)
+	assert.NoError(t, err)
+
+	var functions []string
+	ast.Inspect(file, func(node ast.Node) bool {
+		if expr, ok := node.(*ast.BinaryExpr); ok {
+			functions = append(functions, enclosingFunction(file, expr))
+		}
+
+		return true
+	})
+
+	assert.Equal(t, []string{"", "T.M"}, functions)
+}
+
+func TestMainChangedSince(t *testing.T) {
+	dir := t.TempDir()
+
+	writeTestFile(t, filepath.Join(dir, "go.mod"), "module changed\n\ngo 1.21\n")
+	writeTestFile(t, filepath.Join(dir, "changed.go"), "package changed\n\nfunc Add(a, b int) int {\n\treturn a + b\n}\n\nfunc Sub(a, b int) int {\n\treturn a - b\n}\n")
+	writeTestFile(t, filepath.Join(dir, "changed_test.go"), "package changed\n\nimport \"testing\"\n\nfunc TestAdd(t *testing.T) {\n\tif Add(1, 2) != 3 {\n\t\tt.Fail()\n\t}\n}\n")
+
+	git := func(args ...string) {
+		cmd := exec.Command("git", append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		cmd.Dir = dir
+		out, err := cmd.CombinedOutput()
+		assert.NoError(t, err, string(out))
+	}
+	git("init", "-q")
+	git("add", ".")
+	git("commit", "-q", "-m", "initial")
+
+	testMain(
+		t,
+		dir,
+		[]string{"--changed-since", "HEAD", "."},
+		returnOk,
+		"No Go source files changed since \"HEAD\", there is nothing to mutate",
+	)
+
+	// Only the changed line of Add is mutated, Sub is left alone
+	writeTestFile(t, filepath.Join(dir, "changed.go"), "package changed\n\nfunc Add(a, b int) int {\n\treturn b + a\n}\n\nfunc Sub(a, b int) int {\n\treturn a - b\n}\n")
+
+	testMain(
+		t,
+		dir,
+		[]string{"--changed-since", "HEAD", "."},
+		returnOk,
+		"The mutation score is 1.000000 (1 passed, 0 failed, 0 duplicated, 0 skipped, total is 1)\nThe changed-lines mutation score is 1.000000 (1 mutations of lines changed since HEAD)",
+	)
+
+	// Removals of the unchanged statements of the changed block are left out
+	writeTestFile(t, filepath.Join(dir, "changed.go"), "package changed\n\nfunc Add(a, b int) int {\n\tc := a\n\tc += b\n\tc *= 1\n\treturn c\n}\n")
+	git("commit", "-q", "-a", "-m", "count")
+	writeTestFile(t, filepath.Join(dir, "changed.go"), "package changed\n\nfunc Add(a, b int) int {\n\tc := a\n\tc += b\n\tc /= 1\n\treturn c\n}\n")
+
+	out := testMain(
+		t,
+		dir,
+		[]string{"--changed-since", "HEAD", "--verbose", "."},
+		returnOk,
+		"Skip 1 mutations of blocks",
+	)
+	assert.Contains(t, out, "The mutation score is 0.500000 (2 passed, 2 failed, 0 duplicated, 0 skipped, total is 4)")
+
+	testMain(
+		t,
+		dir,
+		[]string{"--changed-since", "unknown-ref", "."},
+		returnError,
+		`Could not read the changes since "unknown-ref"`,
+	)
+}
+
+func writeTestFile(t *testing.T, path string, content string) {
+	assert.NoError(t, os.WriteFile(path, []byte(content), 0666))
+}
+
+func TestMainFailingBaseline(t *testing.T) {
+	saveReportFileName := models.ReportFileName
+	defer func() {
+		models.ReportFileName = saveReportFileName
+	}()
+	models.ReportFileName = filepath.Join(t.TempDir(), "report.json")
+
+	testMain(
+		t,
+		"../../testdata/baseline/failing",
+		[]string{"."},
+		returnError,
+		"The tests of the following packages do not pass on the original code",
+	)
+}
+
+func TestMainExcludeFailingPackages(t *testing.T) {
+	saveReportFileName := models.ReportFileName
+	defer func() {
+		models.ReportFileName = saveReportFileName
+	}()
+	models.ReportFileName = filepath.Join(t.TempDir(), "report.json")
+
+	testMain(
+		t,
+		"../../testdata/baseline/failing",
+		[]string{"--exclude-failing-packages", "."},
+		returnOk,
+		"The mutation score is 0.000000 (0 passed, 0 failed, 0 duplicated, 0 skipped, total is 0)",
 	)
 }
 
@@ -79,9 +1038,9 @@ func TestMainJSONReport(t *testing.T) {
 	testMain(
 		t,
 		"../../example",
-		[]string{"--debug", "--exec-timeout", "1", "--config", "../testdata/configs/configForJson.yml.test"},
+		[]string{"--debug", "--config", "../testdata/configs/configForJson.yml.test"},
 		returnOk,
-		"The mutation score is 0.583333 (35 passed, 25 failed, 8 duplicated, 0 skipped, total is 60)",
+		"The mutation score is 0.583333 (35 passed, 14 failed, 8 duplicated, 0 skipped, total is 60)\nOf the total, 11 not covered\nThe covered code mutation score is 0.714286, the mutation code coverage is 0.816667",
 	)
 
 	info, err := os.Stat(jsonFile)
@@ -105,32 +1064,88 @@ func TestMainJSONReport(t *testing.T) {
 	expectedStats := models.Stats{
 		TotalMutantsCount:    60,
 		KilledCount:          35,
-		NotCoveredCount:      0,
-		EscapedCount:         25,
+		NotCoveredCount:      11,
+		EscapedCount:         14,
 		ErrorCount:           0,
 		SkippedCount:         0,
 		TimeOutCount:         0,
 		Msi:                  0.5833333333333334,
-		MutationCodeCoverage: 0,
-		CoveredCodeMsi:       0,
+		MutationCodeCoverage: 0.8166666666666667,
+		CoveredCodeMsi:       0.7142857142857143,
 		DuplicatedCount:      0,
 	}
 
 	assert.Equal(t, expectedStats, mutationReport.Stats)
-	assert.Equal(t, 25, len(mutationReport.Escaped))
+	assert.Len(t, mutationReport.Baselines, 1)
+	assert.True(t, mutationReport.Baselines[0].Passed)
+	assert.Greater(t, mutationReport.Baselines[0].Duration, 0.0)
+	assert.NotEmpty(t, mutationReport.Baselines[0].Tests)
+	assert.Equal(t, 14, len(mutationReport.Escaped))
+	assert.Equal(t, 11, len(mutationReport.NotCovered))
 	assert.Nil(t, mutationReport.Timeouted)
 	assert.Equal(t, 35, len(mutationReport.Killed))
 	assert.Nil(t, mutationReport.Errored)
+	assert.Nil(t, mutationReport.BuildFailed)
 
 	for i := 0; i < len(mutationReport.Escaped); i++ {
 		assert.Contains(t, mutationReport.Escaped[i].ProcessOutput, "FAIL")
+		assert.Equal(t, models.StatusEscaped, mutationReport.Escaped[i].Status)
+	}
+	for i := 0; i < len(mutationReport.NotCovered); i++ {
+		assert.Contains(t, mutationReport.NotCovered[i].ProcessOutput, "not covered")
+		assert.Equal(t, models.StatusNotCovered, mutationReport.NotCovered[i].Status)
 	}
 	for i := 0; i < len(mutationReport.Killed); i++ {
 		assert.Contains(t, mutationReport.Killed[i].ProcessOutput, "PASS")
+		assert.Equal(t, models.StatusKilled, mutationReport.Killed[i].Status)
+		assert.NotEmpty(t, mutationReport.Killed[i].KilledBy)
 	}
 }
 
-func testMain(t *testing.T, root string, exec []string, expectedExitCode int, contains string) {
+func TestMainRefusesDisplacedOriginal(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "a.go"), []byte("package a\n"), 0666))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "a.go.tmp"), []byte("package a\n"), 0666))
+
+	testMain(
+		t,
+		dir,
+		[]string{"."},
+		returnError,
+		"which looks like an original source file displaced by an interrupted run",
+	)
+}
+
+func TestRestore(t *testing.T) {
+	dir := t.TempDir()
+	original := filepath.Join(dir, "a.go")
+	assert.NoError(t, os.WriteFile(original, []byte("package a\n"), 0666))
+
+	j, err := journal.Create(journal.PathFor(filepath.Join(dir, "go-mutesting-1")))
+	assert.NoError(t, err)
+	e, err := j.Begin(original)
+	assert.NoError(t, err)
+
+	assert.NoError(t, os.Rename(original, e.Displaced))
+	assert.NoError(t, os.WriteFile(original, []byte("package mutated\n"), 0666))
+
+	testMain(
+		t,
+		dir,
+		[]string{"restore", journal.PathFor(filepath.Join(dir, "go-mutesting-1"))},
+		returnOk,
+		fmt.Sprintf("Restored %q", original),
+	)
+
+	content, err := os.ReadFile(original)
+	assert.NoError(t, err)
+	assert.Equal(t, "package a\n", string(content))
+
+	_, err = os.Stat(journal.PathFor(filepath.Join(dir, "go-mutesting-1")))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func testMain(t *testing.T, root string, exec []string, expectedExitCode int, contains string) string {
 	saveStderr := os.Stderr
 	saveStdout := os.Stdout
 	saveCwd, err := os.Getwd()
@@ -166,4 +1181,6 @@ func testMain(t *testing.T, root string, exec []string, expectedExitCode int, co
 
 	assert.Equal(t, expectedExitCode, exitCode)
 	assert.Contains(t, out, contains)
+
+	return out
 }
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/jessevdk/go-flags"
+
+	"github.com/avito-tech/go-mutesting/internal/models"
+	"github.com/avito-tech/go-mutesting/internal/reportmaker"
+)
+
+func mergeCmd(args []string) int {
+	var opts = &models.MergeOptions{}
+
+	p := flags.NewNamedParser("go-mutesting merge", flags.None)
+
+	p.ShortDescription = "Combine the JSON reports of several runs, e.g. of all shards of a run (see --shard), into one report"
+
+	if _, err := p.AddGroup("merge", "merge arguments", opts); err != nil {
+		return exitError(err.Error())
+	}
+
+	_, err := p.ParseArgs(args)
+	if opts.General.Help {
+		p.WriteHelp(os.Stdout)
+
+		return returnHelp
+	}
+	if err != nil {
+		return exitError(err.Error())
+	}
+
+	var reports []models.Report
+	for _, path := range opts.Remaining.Reports {
+		report, err := reportmaker.ReadJSONReport(path)
+		if err != nil {
+			return exitError("Could not read report %q: %v", path, err)
+		}
+
+		reports = append(reports, report)
+	}
+
+	report, err := reportmaker.Merge(reports)
+	if err != nil {
+		return exitError("Could not merge the reports: %v", err)
+	}
+
+	fmt.Printf("Merged %d reports, the mutation score is %f (%d passed, %d failed, %d skipped, total is %d)\n",
+		len(reports),
+		report.Stats.Msi,
+		report.Stats.KilledCount,
+		report.Stats.EscapedCount,
+		report.Stats.SkippedCount,
+		report.Stats.TotalMutantsCount,
+	)
+
+	if report.Rerun != nil {
+		printRerunDelta(report.Rerun)
+	}
+	if report.Sampling != nil {
+		printSampling(report.Sampling)
+	}
+
+	if err := reportmaker.MakeJSONReport(report); err != nil {
+		return exitError(err.Error())
+	}
+	fmt.Printf("Save report into %q\n", models.ReportFileName)
+
+	if opts.General.HTMLOutput {
+		if err := reportmaker.MakeHTMLReport(report); err != nil {
+			return exitError(err.Error())
+		}
+		fmt.Printf("Save report into %q\n", models.ReportHTMLFileName)
+	}
+
+	return checkGates(report, models.Gates{}, opts.Gate)
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+
+	"github.com/avito-tech/go-mutesting/internal/models"
+	"github.com/avito-tech/go-mutesting/internal/reportmaker"
+)
+
+// loadEscaped returns the escaped mutants of the given JSON report by their IDs.
+func loadEscaped(path string) (map[string]models.Mutant, error) {
+	report, err := reportmaker.ReadJSONReport(path)
+	if err != nil {
+		return nil, err
+	}
+
+	escaped := make(map[string]models.Mutant, len(report.Escaped))
+	for _, mutant := range report.Escaped {
+		// Reports of older versions do not identify mutants by ID
+		if mutant.ID == "" {
+			return nil, fmt.Errorf("escaped mutants of %q have no ID, the report was written by an older version", path)
+		}
+
+		escaped[mutant.ID] = mutant
+	}
+
+	return escaped, nil
+}
+
+// escapedFiles returns the files of the given targets which contain one of the escaped mutants.
+func escapedFiles(files []string, escaped map[string]models.Mutant) []string {
+	paths := make(map[string]struct{})
+	for _, mutant := range escaped {
+		if abs, err := filepath.Abs(mutant.Mutator.OriginalFilePath); err == nil {
+			paths[abs] = struct{}{}
+		}
+	}
+
+	var remaining []string
+	for _, file := range files {
+		abs, err := filepath.Abs(file)
+		if err != nil {
+			continue
+		}
+
+		if _, ok := paths[abs]; ok {
+			remaining = append(remaining, file)
+		}
+	}
+
+	return remaining
+}
+
+// rerunJobs returns the mutations which escaped in the earlier report, and the escaped mutants which no mutation matches anymore.
+// A mutant is stale if its code changed since the report, since its ID changed with it.
+func rerunJobs(jobs []mutantJob, escaped map[string]models.Mutant) ([]mutantJob, []models.Mutant) {
+	var rerun []mutantJob
+	found := make(map[string]struct{})
+
+	for _, job := range jobs {
+		if _, ok := escaped[job.id]; ok {
+			rerun = append(rerun, job)
+			found[job.id] = struct{}{}
+		}
+	}
+
+	var stale []models.Mutant
+	for id, mutant := range escaped {
+		if _, ok := found[id]; !ok {
+			stale = append(stale, mutant)
+		}
+	}
+	sort.Slice(stale, func(i, j int) bool {
+		return stale[i].ID < stale[j].ID
+	})
+
+	return rerun, stale
+}
+
+// rerunDelta compares the results of the re-tested mutations with the earlier report.
+// Mutations which are detected now count as killed, all others as still escaping.
+// Mutations which the exec command skipped are only counted by the report, so they are in neither list.
+func rerunDelta(path string, report *models.Report, escaped map[string]models.Mutant, stale []models.Mutant) *models.RerunDelta {
+	delta := &models.RerunDelta{
+		Report:  path,
+		Total:   len(escaped),
+		Killed:  []string{},
+		Escaped: []string{},
+		Stale:   stale,
+	}
+	if delta.Stale == nil {
+		delta.Stale = []models.Mutant{}
+	}
+
+	detected := [][]models.Mutant{report.Killed, report.Panicked, report.Timeouted, report.Errored}
+	for _, mutants := range detected {
+		for _, mutant := range mutants {
+			if _, ok := escaped[mutant.ID]; ok {
+				delta.Killed = append(delta.Killed, mutant.ID)
+			}
+		}
+	}
+
+	alive := [][]models.Mutant{report.Escaped, report.NotCovered, report.Accepted, report.BuildFailed, report.VetFailed}
+	for _, mutants := range alive {
+		for _, mutant := range mutants {
+			if _, ok := escaped[mutant.ID]; ok {
+				delta.Escaped = append(delta.Escaped, mutant.ID)
+			}
+		}
+	}
+
+	return delta
+}
+
+// printRerunDelta prints how the escaped mutations of the earlier report fared.
+func printRerunDelta(delta *models.RerunDelta) {
+	for _, mutant := range delta.Stale {
+		fmt.Printf("STALE %s of %q (%s), its code changed since the report\n", mutant.ID, mutant.Mutator.OriginalFilePath, mutant.Mutator.MutatorName)
+	}
+
+	fmt.Printf("Of %d mutations which escaped in %q, %d are killed now, %d still escape and %d are stale\n",
+		delta.Total,
+		delta.Report,
+		len(delta.Killed),
+		len(delta.Escaped),
+		len(delta.Stale),
+	)
+}
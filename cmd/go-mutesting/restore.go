@@ -0,0 +1,90 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/jessevdk/go-flags"
+
+	"github.com/avito-tech/go-mutesting/internal/journal"
+	"github.com/avito-tech/go-mutesting/internal/models"
+)
+
+func restoreCmd(args []string) int {
+	var opts = &models.RestoreOptions{}
+
+	p := flags.NewNamedParser("go-mutesting restore", flags.None)
+
+	p.ShortDescription = "Restore original source files which were displaced by an interrupted run"
+
+	if _, err := p.AddGroup("restore", "restore arguments", opts); err != nil {
+		return exitError(err.Error())
+	}
+
+	_, err := p.ParseArgs(args)
+	if opts.General.Help {
+		p.WriteHelp(os.Stdout)
+
+		return returnHelp
+	}
+	if err != nil {
+		return exitError(err.Error())
+	}
+
+	journals := opts.Remaining.Journals
+	if len(journals) == 0 {
+		journals, err = journal.Abandoned(os.TempDir())
+		if err != nil {
+			return exitError("Could not search for journals: %v", err)
+		}
+	}
+
+	if len(journals) == 0 {
+		fmt.Println("There is nothing to restore")
+
+		return returnOk
+	}
+
+	failed := false
+
+	for _, path := range journals {
+		entries, _, err := journal.Load(path)
+		if err != nil {
+			return exitError("Could not read journal %q: %v", path, err)
+		}
+
+		restored := true
+		for _, e := range entries {
+			displaced := journal.IsDisplaced(e)
+
+			if err := journal.Restore(e); err != nil {
+				_, _ = fmt.Fprintf(os.Stderr, "Could not restore %q: %v\n", e.Original, err)
+				restored = false
+
+				continue
+			}
+
+			if displaced {
+				fmt.Printf("Restored %q\n", e.Original)
+			} else {
+				fmt.Printf("%q is already in place\n", e.Original)
+			}
+		}
+
+		if !restored {
+			failed = true
+
+			continue
+		}
+
+		if err := os.Remove(path); err != nil {
+			return exitError("Could not remove journal %q: %v", path, err)
+		}
+	}
+
+	if failed {
+		return returnError
+	}
+
+	return returnOk
+}
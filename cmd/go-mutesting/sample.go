@@ -0,0 +1,105 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/avito-tech/go-mutesting/internal/models"
+	"github.com/avito-tech/go-mutesting/internal/sampling"
+)
+
+// sampleSeed returns the seed of --sample and --time-budget, a new one is picked if none is given.
+func sampleSeed(opts *models.Options) int64 {
+	if opts.Filter.Seed != nil {
+		return *opts.Filter.Seed
+	}
+
+	return time.Now().UnixNano()
+}
+
+// sampleJobs returns a random sample of the given size of the mutations, in their original order.
+// Mutations are picked by a hash of the seed and their ID, so the same seed picks the same mutations as long as they exist.
+func sampleJobs(jobs []mutantJob, size int, seed int64) []mutantJob {
+	keys := make([]string, len(jobs))
+	for i, job := range jobs {
+		keys[i] = job.id
+	}
+
+	picked := sampling.Order(keys, seed)[:size]
+	sort.Ints(picked)
+
+	sampled := make([]mutantJob, 0, size)
+	for _, i := range picked {
+		sampled = append(sampled, jobs[i])
+	}
+
+	return sampled
+}
+
+// prioritizeJobs orders the mutations for --time-budget, so the most valuable ones are tested before the budget runs out.
+// Cached results cost nothing and come first, then mutations of changed lines, then all others in a random order of the seed.
+// The random order keeps the tested mutations of the others a fair sample for the estimated mutation score.
+func prioritizeJobs(jobs []mutantJob, seed int64) []mutantJob {
+	keys := make([]string, len(jobs))
+	for i, job := range jobs {
+		keys[i] = job.id
+	}
+
+	priority := func(job mutantJob) int {
+		switch {
+		case job.cached != nil:
+			return 0
+		case job.onChangedLines:
+			return 1
+		default:
+			return 2
+		}
+	}
+
+	ordered := make([]mutantJob, 0, len(jobs))
+	for _, i := range sampling.Order(keys, seed) {
+		ordered = append(ordered, jobs[i])
+	}
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return priority(ordered[i]) < priority(ordered[j])
+	})
+
+	return ordered
+}
+
+// prioritized tells whether the mutation is tested ahead of the random order under --time-budget, see prioritizeJobs.
+func prioritized(job mutantJob) bool {
+	return job.cached != nil || job.onChangedLines
+}
+
+// estimateMsi sets the interval of the mutation score of all mutations which is estimated from the tested ones.
+// The prioritized mutations are no fair sample, so the estimate is based on the results of the mutations which were tested in a random order only.
+func estimateMsi(s *models.Sampling, random *models.Report) {
+	s.Detected = random.DetectedCount()
+	s.Counted = random.TotalCount()
+
+	sampling.Estimate(s)
+}
+
+// printSampling prints how many mutations were tested and the estimated mutation score of all of them.
+func printSampling(s *models.Sampling) {
+	if s.OverBudget > 0 {
+		fmt.Printf("The time budget of %s ran out, %d of %d mutations were not tested\n", s.TimeBudget, s.OverBudget, s.Sampled)
+	}
+
+	scope := "all of them"
+	if s.Prioritized > 0 {
+		scope = fmt.Sprintf("all but the %d prioritized cached mutations and mutations of changed lines", s.Prioritized)
+	}
+
+	fmt.Printf("%d of %d mutations were tested (seed %d), the mutation score of %s is estimated between %f and %f (%.0f%% confidence)\n",
+		s.Sampled-s.OverBudget,
+		s.Population,
+		s.Seed,
+		scope,
+		s.MsiLow,
+		s.MsiHigh,
+		s.ConfidenceLevel*100,
+	)
+}
@@ -0,0 +1,222 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/avito-tech/go-mutesting/internal/console"
+	"github.com/avito-tech/go-mutesting/internal/models"
+	"github.com/avito-tech/go-mutesting/internal/runner"
+	"github.com/avito-tech/go-mutesting/internal/schemata"
+)
+
+// schemataHelperFile is added to every package with schemata and tells which mutation is active
+const schemataHelperFile = "mutesting_schemata.go"
+
+// maxSchemataBuilds limits how often the schemata of a package are built again without the mutations which broke the build
+const maxSchemataBuilds = 10
+
+var compileErrorRegex = regexp.MustCompile(`(?m)^(.+?\.go):(\d+):`)
+
+type schemataBuild struct {
+	binary   string
+	excluded map[string]bool
+	err      error
+}
+
+// buildSchemata compiles the schemata of the mutations of every package into one test binary.
+// Mutations which break the build are left out and tested one by one, as are all mutations of packages whose schemata do not build at all.
+func buildSchemata(opts *models.Options, tmpDir string, jobs []mutantJob, schemas map[string]*schemata.File, importPaths map[string]string) {
+	var packages []string
+	files := make(map[string][]string)
+	seen := make(map[string]struct{})
+
+	for _, job := range jobs {
+		if job.schemaID == "" || job.notCovered || job.cached != nil {
+			continue
+		}
+
+		pkgName := job.pkg.Path()
+		if _, ok := files[pkgName]; !ok {
+			packages = append(packages, pkgName)
+		}
+		if _, ok := seen[job.originalFile]; !ok {
+			seen[job.originalFile] = struct{}{}
+			files[pkgName] = append(files[pkgName], job.originalFile)
+		}
+	}
+
+	console.Verbose(opts, "Build the schemata of %d packages", len(packages))
+
+	runner.Run(runner.Workers(opts.Exec.Jobs), len(packages), func(i int) schemataBuild {
+		pkgName := packages[i]
+
+		var name string
+		for _, job := range jobs {
+			if job.pkg.Path() == pkgName {
+				name = job.pkg.Name()
+
+				break
+			}
+		}
+
+		return buildPackageSchemata(filepath.Join(tmpDir, "schemata", strconv.Itoa(i)), name, files[pkgName], schemas)
+	}, func(i int, result schemataBuild) {
+		pkgName := packages[i]
+
+		if result.err != nil {
+			fmt.Printf("Could not build the schemata of %q, its mutations are tested one by one: %v\n", pkgName, result.err)
+
+			return
+		}
+
+		importPath, ok := importPaths[pkgName]
+		if !ok {
+			importPath = pkgName
+		}
+
+		count := 0
+		for j := range jobs {
+			job := &jobs[j]
+			if job.pkg.Path() != pkgName || job.schemaID == "" || job.notCovered || job.cached != nil {
+				continue
+			}
+
+			if result.excluded[job.schemaID] {
+				console.Debug(opts, "%q breaks the schemata build, it is tested on its own", job.mutationFile)
+
+				continue
+			}
+
+			job.schemaBinary = result.binary
+			job.schemaPackage = importPath
+			count++
+		}
+
+		console.Verbose(opts, "Built the schemata of %q with %d mutations", pkgName, count)
+	})
+}
+
+// buildPackageSchemata builds the test binary of a package with the schemata of the given files into dir.
+func buildPackageSchemata(dir string, pkgName string, files []string, schemas map[string]*schemata.File) schemataBuild {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return schemataBuild{err: err}
+	}
+
+	pkgDir, err := filepath.Abs(filepath.Dir(files[0]))
+	if err != nil {
+		return schemataBuild{err: err}
+	}
+
+	helperFile := filepath.Join(dir, schemataHelperFile)
+	if err := os.WriteFile(helperFile, schemata.HelperSource(pkgName), 0666); err != nil {
+		return schemataBuild{err: err}
+	}
+
+	binary := filepath.Join(dir, "schemata.test")
+	overlayFile := filepath.Join(dir, "overlay.json")
+	excluded := make(map[string]bool)
+
+	for build := 0; build < maxSchemataBuilds; build++ {
+		replace := map[string]string{
+			filepath.Join(pkgDir, schemataHelperFile): helperFile,
+		}
+		regions := make(map[string][]schemata.Region)
+
+		for i, file := range files {
+			source, fileRegions := schemas[file].Render(excluded)
+
+			renderedFile := filepath.Join(dir, fmt.Sprintf("%d.%s", i, filepath.Base(file)))
+			if err := os.WriteFile(renderedFile, source, 0666); err != nil {
+				return schemataBuild{err: err}
+			}
+
+			replace[file] = renderedFile
+			regions[filepath.Join(pkgDir, filepath.Base(file))] = fileRegions
+		}
+
+		if err := runner.WriteOverlayFiles(overlayFile, replace); err != nil {
+			return schemataBuild{err: err}
+		}
+
+		// Vet runs as part of the build, so mutations which do not pass vet are tested on their own as well
+		cmd := exec.Command("go", "test", "-c", "-o", binary, "-overlay", overlayFile, ".")
+		cmd.Dir = pkgDir
+		cmd.Env = os.Environ()
+
+		output, err := cmd.CombinedOutput()
+		if err == nil {
+			if _, err := os.Stat(binary); err != nil {
+				return schemataBuild{err: errors.New("the package has no tests")}
+			}
+
+			return schemataBuild{binary: binary, excluded: excluded}
+		}
+
+		var exitErr *exec.ExitError
+		if !errors.As(err, &exitErr) {
+			return schemataBuild{err: err}
+		}
+
+		broken := brokenMutations(output, pkgDir, regions)
+		if len(broken) == 0 {
+			return schemataBuild{err: fmt.Errorf("%s", output)}
+		}
+
+		for _, id := range broken {
+			excluded[id] = true
+		}
+	}
+
+	return schemataBuild{err: fmt.Errorf("still does not build after leaving out %d mutations", len(excluded))}
+}
+
+// brokenMutations returns the mutations whose schemata caused the given compile errors.
+func brokenMutations(output []byte, pkgDir string, regions map[string][]schemata.Region) []string {
+	var broken []string
+
+	for _, match := range compileErrorRegex.FindAllSubmatch(output, -1) {
+		file := string(match[1])
+		if !filepath.IsAbs(file) {
+			file = filepath.Join(pkgDir, file)
+		}
+
+		fileRegions, ok := regions[file]
+		if !ok {
+			continue
+		}
+
+		line, err := strconv.Atoi(string(match[2]))
+		if err != nil {
+			continue
+		}
+
+		broken = append(broken, schemata.Locate(fileRegions, line)...)
+	}
+
+	return broken
+}
+
+// schemataTest runs the schemata test binary of the package of the given mutation with the mutation activated.
+func schemataTest(opts *models.Options, job mutantJob, timeout time.Duration) runner.GoTest {
+	// Tests expect to run in the directory of their package
+	test := runner.GoTest{
+		Binary:   job.schemaBinary,
+		Package:  job.schemaPackage,
+		Dir:      filepath.Dir(job.originalFile),
+		Env:      []string{schemata.ActiveEnv + "=" + job.schemaID},
+		FailFast: !opts.Test.KillMatrix,
+		Timeout:  timeout,
+	}
+	if len(job.tests) > 0 {
+		test.RunPattern = testsPattern(job.tests)
+	}
+
+	return test
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/avito-tech/go-mutesting/internal/console"
+	"github.com/avito-tech/go-mutesting/internal/importing"
+	"github.com/avito-tech/go-mutesting/internal/models"
+)
+
+// Test scopes of --test-scope
+const (
+	// scopePackage runs the tests of the mutated package
+	scopePackage = "package"
+	// scopeDependents runs the tests of the packages of the module which depend on the mutated package as well
+	scopeDependents = "dependents"
+)
+
+// assignDependents sets the packages of the module which depend on the package of every mutation, their tests test the mutation as well.
+// The module is the one of the mutated package, which is not necessarily the one of the current directory, e.g. in a workspace.
+func assignDependents(opts *models.Options, jobs []mutantJob, importPaths map[string]string) {
+	dirs := make(map[string]string)
+	for _, job := range jobs {
+		dirs[job.pkg.Path()] = filepath.Dir(job.originalFile)
+	}
+
+	// modules packages of every module by its root directory, nil if they could not be listed
+	modules := make(map[string][]importing.ModulePackage)
+
+	dependents := make(map[string][]string)
+	for _, pkgName := range sortedPackages(jobs) {
+		importPath, ok := importPaths[pkgName]
+		if !ok {
+			continue
+		}
+
+		root, err := importing.ModuleRoot(dirs[pkgName])
+		if err != nil {
+			fmt.Printf("Could not find the module of %q, only its own tests are run: %v\n", importPath, err)
+
+			continue
+		}
+
+		packages, ok := modules[root]
+		if !ok {
+			packages, err = importing.ListModule(root)
+			if err != nil {
+				fmt.Printf("Could not list the packages of the module %q, only the tests of its mutated packages are run: %v\n", root, err)
+			}
+			modules[root] = packages
+		}
+		if packages == nil {
+			continue
+		}
+
+		dependents[pkgName] = importing.Dependents(packages, importPath, opts.Test.ScopeDepth, opts.Test.ScopeMaxPackages)
+		console.Verbose(opts, "Run the tests of %d packages which depend on %q as well", len(dependents[pkgName]), importPath)
+	}
+
+	for i := range jobs {
+		jobs[i].dependents = dependents[jobs[i].pkg.Path()]
+	}
+}
@@ -0,0 +1,173 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/avito-tech/go-mutesting/internal/console"
+	"github.com/avito-tech/go-mutesting/internal/coverage"
+	"github.com/avito-tech/go-mutesting/internal/models"
+	"github.com/avito-tech/go-mutesting/internal/parser"
+	"github.com/avito-tech/go-mutesting/internal/runner"
+)
+
+// coverRun records the coverage of one test on the original code of a mutated package
+type coverRun struct {
+	pkg  string
+	test models.Test
+}
+
+type coverResult struct {
+	profile coverage.Profile
+	err     error
+}
+
+// selectTests assigns every mutation the tests which execute its changed lines on the original code.
+// Mutations whose package coverage could not be recorded, or whose changed lines are not executed by any test, keep the whole test suite.
+func selectTests(
+	opts *models.Options,
+	tmpDir string,
+	jobs []mutantJob,
+	baselines map[string]models.Baseline,
+	timeouts map[string]time.Duration,
+	importPaths map[string]string,
+) {
+	profiles := make(map[string]map[models.Test]coverage.Profile)
+	failed := make(map[string]bool)
+
+	var runs []coverRun
+	for _, pkgName := range sortedPackages(jobs) {
+		if _, ok := importPaths[pkgName]; !ok {
+			continue
+		}
+
+		for _, test := range baselines[pkgName].Tests {
+			// Subtests are covered by their top-level test
+			if !strings.Contains(test.Name, "/") {
+				runs = append(runs, coverRun{pkg: pkgName, test: test})
+			}
+		}
+	}
+
+	console.Verbose(opts, "Record the coverage of %d tests", len(runs))
+
+	runner.Run(runner.Workers(opts.Exec.Jobs), len(runs), func(i int) coverResult {
+		run := runs[i]
+		profileFile := filepath.Join(tmpDir, fmt.Sprintf("coverage.%d.out", i))
+
+		cmd := exec.Command("go", "test", "-run", testsPattern([]models.Test{run.test}), "-coverpkg", importPaths[run.pkg], "-coverprofile", profileFile, run.test.Package)
+		cmd.Env = os.Environ()
+		output := &strings.Builder{}
+		cmd.Stdout = output
+		cmd.Stderr = output
+
+		exitCode, err := runner.Execute(cmd, timeouts[run.pkg])
+		if err != nil {
+			return coverResult{err: err}
+		}
+		if exitCode != 0 {
+			return coverResult{err: fmt.Errorf("go test exited with %d: %s", exitCode, output)}
+		}
+
+		profile, err := coverage.ReadProfile(profileFile)
+
+		return coverResult{profile: profile, err: err}
+	}, func(i int, result coverResult) {
+		run := runs[i]
+
+		if result.err != nil {
+			fmt.Printf("Could not record the coverage of %s of %q, its mutations run all tests: %v\n", run.test.Name, run.pkg, result.err)
+			failed[run.pkg] = true
+
+			return
+		}
+
+		if profiles[run.pkg] == nil {
+			profiles[run.pkg] = make(map[models.Test]coverage.Profile)
+		}
+		profiles[run.pkg][run.test] = result.profile
+	})
+
+	for i := range jobs {
+		job := &jobs[i]
+		pkgName := job.pkg.Path()
+
+		if failed[pkgName] || len(profiles[pkgName]) == 0 || job.notCovered {
+			continue
+		}
+
+		lines := parser.ChangedLines(mutationDiff(job.originalFile, job.mutationFile))
+		file, _ := coverageFile(*job, importPaths)
+
+		for _, run := range runs {
+			if run.pkg != pkgName {
+				continue
+			}
+
+			if profile, ok := profiles[pkgName][run.test]; ok && profile.Covers(file, lines) {
+				job.tests = append(job.tests, run.test)
+			}
+		}
+
+		console.Debug(opts, "Select %d tests for %q", len(job.tests), job.mutationFile)
+	}
+}
+
+// sortedPackages returns the packages of the given mutations.
+func sortedPackages(jobs []mutantJob) []string {
+	var packages []string
+	seen := make(map[string]struct{})
+
+	for _, job := range jobs {
+		if _, ok := seen[job.pkg.Path()]; ok {
+			continue
+		}
+
+		seen[job.pkg.Path()] = struct{}{}
+		packages = append(packages, job.pkg.Path())
+	}
+
+	sort.Strings(packages)
+
+	return packages
+}
+
+// testsPattern returns a pattern for go test -run which matches exactly the given top-level tests.
+func testsPattern(tests []models.Test) string {
+	names := make([]string, 0, len(tests))
+	seen := make(map[string]struct{})
+
+	for _, test := range tests {
+		if _, ok := seen[test.Name]; ok {
+			continue
+		}
+
+		seen[test.Name] = struct{}{}
+		names = append(names, regexp.QuoteMeta(test.Name))
+	}
+
+	return "^(" + strings.Join(names, "|") + ")$"
+}
+
+// testsPackages returns the packages of the given tests.
+func testsPackages(tests []models.Test) []string {
+	var packages []string
+	seen := make(map[string]struct{})
+
+	for _, test := range tests {
+		if _, ok := seen[test.Package]; ok {
+			continue
+		}
+
+		seen[test.Package] = struct{}{}
+		packages = append(packages, test.Package)
+	}
+
+	return packages
+}
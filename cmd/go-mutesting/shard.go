@@ -0,0 +1,48 @@
+package main
+
+import (
+	"fmt"
+	"hash/fnv"
+	"strconv"
+	"strings"
+)
+
+// parseShard parses a shard of the form "k/n", shards are counted from 1.
+func parseShard(shard string) (int, int, error) {
+	k, n, ok := strings.Cut(shard, "/")
+	if !ok {
+		return 0, 0, fmt.Errorf("shard %q is not of the form k/n", shard)
+	}
+
+	index, err := strconv.Atoi(k)
+	if err != nil {
+		return 0, 0, fmt.Errorf("shard %q is not of the form k/n", shard)
+	}
+	count, err := strconv.Atoi(n)
+	if err != nil {
+		return 0, 0, fmt.Errorf("shard %q is not of the form k/n", shard)
+	}
+
+	if count < 1 || index < 1 || index > count {
+		return 0, 0, fmt.Errorf("shard %q does not exist, k has to be between 1 and n", shard)
+	}
+
+	return index, count, nil
+}
+
+// shardJobs returns the mutations of shard k of n.
+// Mutations are assigned by the hash of their ID, so every machine assigns them the same way and the shards of large files are spread over all machines.
+func shardJobs(jobs []mutantJob, k int, n int) []mutantJob {
+	var sharded []mutantJob
+
+	for _, job := range jobs {
+		h := fnv.New32a()
+		_, _ = h.Write([]byte(job.id))
+
+		if int(h.Sum32()%uint32(n)) == k-1 {
+			sharded = append(sharded, job)
+		}
+	}
+
+	return sharded
+}
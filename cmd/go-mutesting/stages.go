@@ -0,0 +1,146 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/avito-tech/go-mutesting/internal/console"
+	"github.com/avito-tech/go-mutesting/internal/journal"
+	"github.com/avito-tech/go-mutesting/internal/models"
+	"github.com/avito-tech/go-mutesting/internal/parser"
+	"github.com/avito-tech/go-mutesting/internal/runner"
+)
+
+// checkStages validates the test stages of the config file.
+func checkStages(opts *models.Options) error {
+	names := make(map[string]struct{})
+
+	for i, stage := range opts.Config.Stages {
+		if stage.Name == "" {
+			return fmt.Errorf("test stage %d has no name", i+1)
+		}
+		if _, ok := names[stage.Name]; ok {
+			return fmt.Errorf("test stage %q is defined twice", stage.Name)
+		}
+		names[stage.Name] = struct{}{}
+
+		if (len(stage.Flags) > 0 || len(stage.Tags) > 0) && (stage.Exec != "" || opts.Exec.Exec != "") {
+			return fmt.Errorf("flags and tags of test stage %q need the built-in exec command", stage.Name)
+		}
+	}
+
+	return nil
+}
+
+// stageFlags returns the additional arguments of go test of the given stage.
+func stageFlags(stage models.Stage) []string {
+	var flags []string
+	if len(stage.Tags) > 0 {
+		flags = append(flags, "-tags", strings.Join(stage.Tags, ","))
+	}
+
+	return append(flags, stage.Flags...)
+}
+
+// baselineStages tests the original code of a package with one test stage after another, until a stage does not pass.
+// The coverage and the tests of the baseline are those of the first stage, the duration is the one of the slowest stage.
+func baselineStages(
+	opts *models.Options,
+	out io.Writer,
+	job mutantJob,
+	execs []string,
+	swaps *journal.Journal,
+) (result runner.TestResult, failedStage string, duration time.Duration) {
+	for i, stage := range opts.Config.Stages {
+		stageJob := job
+		stageExecs := execs
+		// Without an explicit timeout there is nothing yet to derive one from
+		stageTimeout := time.Duration(opts.Exec.Timeout) * time.Second
+
+		if i > 0 {
+			stageJob.coverPackage = ""
+			stageJob.coverProfile = ""
+		}
+		if stage.Exec != "" {
+			stageExecs = strings.Split(stage.Exec, " ")
+		}
+		if stage.Timeout > 0 {
+			stageTimeout = stage.Timeout
+		}
+		stageJob.testFlags = stageFlags(stage)
+
+		start := time.Now()
+		stageResult := mutateExec(opts, out, stageJob, stageTimeout, stageExecs, swaps, &models.Mutant{})
+		duration = max(duration, time.Since(start))
+
+		if stageResult.Status != models.StatusEscaped {
+			return stageResult, stage.Name, duration
+		}
+		if i == 0 {
+			result = stageResult
+		}
+	}
+
+	return result, "", duration
+}
+
+// mutateStages tests the mutation with one test stage after another, until a stage does not let it survive.
+// The coverage and the selected tests of a mutation are those of the tests of the first stage, so later stages run all of their tests.
+func mutateStages(
+	opts *models.Options,
+	out io.Writer,
+	job mutantJob,
+	timeout time.Duration,
+	execs []string,
+	swaps *journal.Journal,
+	mutant *models.Mutant,
+) runner.TestResult {
+	var result runner.TestResult
+
+	for i, stage := range opts.Config.Stages {
+		stageJob := job
+		stageExecs := execs
+		stageTimeout := timeout
+
+		if i == 0 && job.notCovered {
+			// Slower stages may execute code which the tests of the first stage do not
+			diff := mutationDiff(job.originalFile, job.mutationFile)
+			mutant.Diff = string(diff)
+			mutant.Mutator.OriginalStartLine = parser.FindOriginalStartLine(diff)
+			mutant.Stage = stage.Name
+
+			result = runner.TestResult{Status: models.StatusNotCovered}
+			console.Fverbose(out, opts, "Mutation is not covered by stage %s", stage.Name)
+
+			continue
+		}
+		if i > 0 {
+			stageJob.tests = nil
+		}
+
+		if stage.Exec != "" {
+			stageExecs = strings.Split(stage.Exec, " ")
+		}
+		if stage.Timeout > 0 {
+			stageTimeout = stage.Timeout
+		}
+		stageJob.testFlags = stageFlags(stage)
+		if len(stageJob.testFlags) > 0 {
+			// The schemata test binary was built without the flags of the stage
+			stageJob.schemaBinary = ""
+		}
+
+		console.Fverbose(out, opts, "Test mutation with stage %s", stage.Name)
+
+		result = mutateExec(opts, out, stageJob, stageTimeout, stageExecs, swaps, mutant)
+		mutant.Stage = stage.Name
+
+		if result.Status != models.StatusEscaped {
+			break
+		}
+	}
+
+	return result
+}
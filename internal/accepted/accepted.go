@@ -0,0 +1,158 @@
+package accepted
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/avito-tech/go-mutesting/internal/models"
+)
+
+// DateLayout layout of the expiry dates of entries
+const DateLayout = "2006-01-02"
+
+// File accepted mutations file, a YAML (or JSON) document with the surviving mutations which do not count against the score
+type File struct {
+	Mutants []models.Acceptance `yaml:"mutants" json:"mutants"`
+}
+
+// Load reads and validates the accepted mutations file at the given path.
+func Load(path string) (*File, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var file File
+	if err := yaml.Unmarshal(content, &file); err != nil {
+		return nil, fmt.Errorf("could not parse accepted mutations file %q: %w", path, err)
+	}
+
+	for i, entry := range file.Mutants {
+		if entry.ID == "" {
+			return nil, fmt.Errorf("entry %d of accepted mutations file %q has no id", i+1, path)
+		}
+		if entry.Reason == "" {
+			return nil, fmt.Errorf("entry %s of accepted mutations file %q has no reason", entry.ID, path)
+		}
+		if entry.Expires != "" {
+			if _, err := time.Parse(DateLayout, entry.Expires); err != nil {
+				return nil, fmt.Errorf("entry %s of accepted mutations file %q expires on %q which is not a YYYY-MM-DD date", entry.ID, path, entry.Expires)
+			}
+		}
+	}
+
+	return &file, nil
+}
+
+// Active returns the entries which did not expire at the given time by the IDs of their mutations, and the expired entries.
+// An entry expires at the start of its expiry date.
+func (f *File) Active(now time.Time) (map[string]models.Acceptance, []models.Acceptance) {
+	active := make(map[string]models.Acceptance, len(f.Mutants))
+	var expired []models.Acceptance
+
+	today := now.Format(DateLayout)
+
+	for _, entry := range f.Mutants {
+		// Dates of the layout compare like strings
+		if entry.Expires != "" && entry.Expires <= today {
+			expired = append(expired, entry)
+
+			continue
+		}
+
+		active[entry.ID] = entry
+	}
+
+	return active, expired
+}
+
+// Append adds the given entries to the accepted mutations file at the given path, which is created if it does not exist.
+// JSON files are rewritten, YAML files keep the comments and formatting of their existing entries.
+func Append(path string, entries []models.Acceptance) error {
+	content, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if len(bytes.TrimSpace(content)) == 0 {
+		content = nil
+	}
+
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		var file File
+		if content != nil {
+			if err := json.Unmarshal(content, &file); err != nil {
+				return fmt.Errorf("could not parse accepted mutations file %q: %w", path, err)
+			}
+		}
+		file.Mutants = append(file.Mutants, entries...)
+
+		out, err := json.MarshalIndent(file, "", "  ")
+		if err != nil {
+			return err
+		}
+
+		return os.WriteFile(path, append(out, '\n'), 0666)
+	}
+
+	var doc yaml.Node
+	if content != nil {
+		if err := yaml.Unmarshal(content, &doc); err != nil {
+			return fmt.Errorf("could not parse accepted mutations file %q: %w", path, err)
+		}
+	}
+	if len(doc.Content) == 0 {
+		doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}}}
+	}
+
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return fmt.Errorf("accepted mutations file %q is not a mapping", path)
+	}
+
+	var mutants *yaml.Node
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		if root.Content[i].Value == "mutants" {
+			mutants = root.Content[i+1]
+		}
+	}
+	switch {
+	case mutants == nil:
+		mutants = &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+		root.Content = append(root.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: "mutants"}, mutants)
+	case mutants.Kind == yaml.ScalarNode && mutants.Tag == "!!null":
+		// "mutants:" without entries
+		*mutants = yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+	case mutants.Kind != yaml.SequenceNode:
+		return fmt.Errorf("mutants of accepted mutations file %q are not a sequence", path)
+	}
+	// Appended entries are written in block style, even if the file had "mutants: []"
+	mutants.Style = 0
+
+	for _, entry := range entries {
+		var node yaml.Node
+		if err := node.Encode(entry); err != nil {
+			return err
+		}
+
+		mutants.Content = append(mutants.Content, &node)
+	}
+
+	var out bytes.Buffer
+	encoder := yaml.NewEncoder(&out)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&doc); err != nil {
+		return err
+	}
+	if err := encoder.Close(); err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, out.Bytes(), 0666)
+}
@@ -0,0 +1,103 @@
+package accepted
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/avito-tech/go-mutesting/internal/models"
+)
+
+func TestLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "accepted.yml")
+	require.NoError(t, os.WriteFile(path, []byte(`mutants:
+  - id: a
+    mutator: branch/if
+    file: example/example.go:17
+    reason: early exit
+    owner: team-a
+  - id: b
+    reason: equivalent
+    expires: 2026-01-01
+`), 0666))
+
+	file, err := Load(path)
+	require.NoError(t, err)
+
+	active, expired := file.Active(time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC))
+	assert.Equal(t, map[string]models.Acceptance{
+		"a": {ID: "a", Mutator: "branch/if", File: "example/example.go:17", Reason: "early exit", Owner: "team-a"},
+	}, active)
+	assert.Equal(t, []models.Acceptance{{ID: "b", Reason: "equivalent", Expires: "2026-01-01"}}, expired)
+
+	active, expired = file.Active(time.Date(2025, 12, 31, 12, 0, 0, 0, time.UTC))
+	assert.Len(t, active, 2)
+	assert.Empty(t, expired)
+}
+
+func TestLoadJSON(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "accepted.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"mutants": [{"id": "a", "reason": "early exit"}]}`), 0666))
+
+	file, err := Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, []models.Acceptance{{ID: "a", Reason: "early exit"}}, file.Mutants)
+}
+
+func TestLoadInvalid(t *testing.T) {
+	for name, content := range map[string]string{
+		"no id":        "mutants:\n  - reason: early exit\n",
+		"no reason":    "mutants:\n  - id: a\n",
+		"invalid date": "mutants:\n  - id: a\n    reason: early exit\n    expires: tomorrow\n",
+	} {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "accepted.yml")
+			require.NoError(t, os.WriteFile(path, []byte(content), 0666))
+
+			_, err := Load(path)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestAppend(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "accepted.yml")
+	require.NoError(t, os.WriteFile(path, []byte(`# reviewed by team-a
+mutants:
+  - id: a
+    reason: early exit # equivalent
+`), 0666))
+
+	require.NoError(t, Append(path, []models.Acceptance{{ID: "b", Mutator: "branch/if", Reason: "logging", Expires: "2026-01-01"}}))
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "# reviewed by team-a")
+	assert.Contains(t, string(content), "reason: early exit # equivalent")
+
+	file, err := Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, []models.Acceptance{
+		{ID: "a", Reason: "early exit"},
+		{ID: "b", Mutator: "branch/if", Reason: "logging", Expires: "2026-01-01"},
+	}, file.Mutants)
+}
+
+func TestAppendNew(t *testing.T) {
+	for _, name := range []string{"accepted.yml", "accepted.json"} {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), name)
+
+			require.NoError(t, Append(path, []models.Acceptance{{ID: "a", Reason: "early exit"}}))
+			require.NoError(t, Append(path, []models.Acceptance{{ID: "b", Reason: "logging"}}))
+
+			file, err := Load(path)
+			require.NoError(t, err)
+			assert.Equal(t, []models.Acceptance{{ID: "a", Reason: "early exit"}, {ID: "b", Reason: "logging"}}, file.Mutants)
+		})
+	}
+}
@@ -3,11 +3,7 @@ package annotation
 import (
 	"go/ast"
 	"go/token"
-	"go/types"
 	"strings"
-
-	"github.com/avito-tech/go-mutesting/internal/filter"
-	"github.com/avito-tech/go-mutesting/mutator"
 )
 
 // Annotation constants define the comment patterns used to disable mutations
@@ -74,19 +70,6 @@ func (p *Processor) ShouldSkip(node ast.Node, mutatorName string) bool {
 		p.LineAnnotation.filterNodesOnNextLine(node, mutatorName)
 }
 
-// DecoratorFilter creates a mutator that applies one or more filters before executing the provided mutator.
-func DecoratorFilter(m mutator.Mutator, name string, filters ...filter.NodeFilter) mutator.Mutator {
-	return func(pkg *types.Package, info *types.Info, node ast.Node) []mutator.Mutation {
-		for _, f := range filters {
-			if f.ShouldSkip(node, name) {
-				return nil
-			}
-		}
-
-		return m(pkg, info, node)
-	}
-}
-
 // getAnnotationName identifies the type of annotation
 func getAnnotationName(comment *ast.Comment) string {
 	content := strings.TrimSpace(comment.Text)
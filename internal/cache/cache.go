@@ -0,0 +1,120 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/avito-tech/go-mutesting/internal/models"
+)
+
+// version is part of every key, so results of older formats are never reused
+const version = "1"
+
+// Entry is the cached result of one mutation.
+type Entry struct {
+	Status            models.MutantStatus `json:"status"`
+	KilledBy          []models.Test       `json:"killedBy,omitempty"`
+	Diff              string              `json:"diff"`
+	OriginalStartLine int64               `json:"originalStartLine"`
+	Stage             string              `json:"stage,omitempty"`
+}
+
+// Cache stores the results of mutations in a directory.
+// Entries are addressed by a key which hashes everything the result depends on, so they never have to be invalidated.
+type Cache struct {
+	dir string
+}
+
+// Open opens the cache in the given directory, which is created if it does not exist.
+func Open(dir string) (*Cache, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("could not create cache directory %q: %w", dir, err)
+	}
+
+	return &Cache{dir: dir}, nil
+}
+
+// Get returns the entry of the given key.
+func (c *Cache) Get(key string) (Entry, bool, error) {
+	content, err := os.ReadFile(c.path(key))
+	if errors.Is(err, fs.ErrNotExist) {
+		return Entry{}, false, nil
+	} else if err != nil {
+		return Entry{}, false, err
+	}
+
+	var entry Entry
+	if err := json.Unmarshal(content, &entry); err != nil {
+		// A broken entry is just a miss, it is overwritten by the next result
+		return Entry{}, false, nil
+	}
+
+	return entry, true, nil
+}
+
+// Put stores the entry of the given key.
+func (c *Cache) Put(key string, entry Entry) error {
+	content, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	path := c.path(key)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	// Concurrent runs may store the same key, so the entry is written under a temporary name and renamed into place
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = os.Remove(tmp.Name())
+	}()
+
+	if _, err := tmp.Write(content); err != nil {
+		_ = tmp.Close()
+
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+// path spreads the entries over subdirectories to keep directories small
+func (c *Cache) path(key string) string {
+	return filepath.Join(c.dir, key[:2], key+".json")
+}
+
+// Key returns the key of a result which depends on the given parts.
+func Key(parts ...string) string {
+	h := sha256.New()
+
+	for _, part := range append([]string{version}, parts...) {
+		// The length keeps the boundaries of the parts apart
+		_, _ = fmt.Fprintf(h, "%d:%s", len(part), part)
+	}
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Cacheable tells if a result with the given status can be reused.
+// Timeouts depend on the duration of the tests of the run, so whether a mutation times out again is not known.
+func Cacheable(status models.MutantStatus) bool {
+	switch status {
+	case models.StatusError, models.StatusNotCovered, models.StatusTimedOut, "":
+		return false
+	}
+
+	return true
+}
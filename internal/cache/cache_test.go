@@ -0,0 +1,62 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/avito-tech/go-mutesting/internal/models"
+)
+
+func TestCache(t *testing.T) {
+	c, err := Open(filepath.Join(t.TempDir(), "cache"))
+	require.NoError(t, err)
+
+	key := Key("fingerprint", "mutation")
+
+	_, ok, err := c.Get(key)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	entry := Entry{
+		Status:            models.StatusKilled,
+		KilledBy:          []models.Test{{Package: "example", Name: "TestFoo"}},
+		Diff:              "@@ -1 +1 @@",
+		OriginalStartLine: 7,
+	}
+	require.NoError(t, c.Put(key, entry))
+
+	cached, ok, err := c.Get(key)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, entry, cached)
+}
+
+func TestCacheBrokenEntry(t *testing.T) {
+	c, err := Open(t.TempDir())
+	require.NoError(t, err)
+
+	key := Key("mutation")
+	require.NoError(t, os.MkdirAll(filepath.Dir(c.path(key)), 0755))
+	require.NoError(t, os.WriteFile(c.path(key), []byte("{"), 0666))
+
+	_, ok, err := c.Get(key)
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestKey(t *testing.T) {
+	assert.Equal(t, Key("a", "b"), Key("a", "b"))
+	assert.NotEqual(t, Key("a", "b"), Key("b", "a"))
+	assert.NotEqual(t, Key("ab", ""), Key("a", "b"))
+}
+
+func TestCacheable(t *testing.T) {
+	assert.True(t, Cacheable(models.StatusKilled))
+	assert.False(t, Cacheable(models.StatusTimedOut))
+	assert.False(t, Cacheable(models.StatusError))
+	assert.False(t, Cacheable(models.StatusNotCovered))
+}
@@ -0,0 +1,153 @@
+package cache
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// maxEntrySize limits the size of entries which are sent over HTTP
+const maxEntrySize = 1 << 20
+
+// httpTimeout limits every request to a cache server, so an unreachable server cannot stall a run
+const httpTimeout = 30 * time.Second
+
+var keyRegex = regexp.MustCompile(`^[0-9a-f]{64}$`)
+
+// ValidKey tells if the given string is a key as returned by Key.
+func ValidKey(key string) bool {
+	return keyRegex.MatchString(key)
+}
+
+// HTTP is a store on a server which speaks the cache protocol.
+// The entry of a key is read with GET and written with PUT at <url>/<key>, a missing entry is answered with 404.
+type HTTP struct {
+	url    string
+	client *http.Client
+}
+
+// NewHTTP returns the store of the cache server at the given URL.
+func NewHTTP(rawURL string) (*HTTP, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse cache URL %q: %w", rawURL, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("cache URL %q must be a http or https URL", rawURL)
+	}
+
+	return &HTTP{
+		url: strings.TrimSuffix(rawURL, "/"),
+		client: &http.Client{
+			Timeout: httpTimeout,
+		},
+	}, nil
+}
+
+// Get implements the Get method of the Store interface
+func (h *HTTP) Get(key string) (Entry, bool, error) {
+	resp, err := h.client.Get(h.url + "/" + key)
+	if err != nil {
+		return Entry{}, false, err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return Entry{}, false, nil
+	default:
+		return Entry{}, false, fmt.Errorf("cache server answered GET with %s", resp.Status)
+	}
+
+	var entry Entry
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxEntrySize)).Decode(&entry); err != nil {
+		return Entry{}, false, fmt.Errorf("cache server sent a broken entry: %w", err)
+	}
+
+	return entry, true, nil
+}
+
+// Put implements the Put method of the Store interface
+func (h *HTTP) Put(key string, entry Entry) error {
+	content, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPut, h.url+"/"+key, bytes.NewReader(content))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("cache server answered PUT with %s", resp.Status)
+	}
+
+	return nil
+}
+
+// Handler serves the entries of the given store over the cache protocol.
+func Handler(store Store) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := strings.TrimPrefix(r.URL.Path, "/")
+		if !ValidKey(key) {
+			http.Error(w, "invalid key", http.StatusBadRequest)
+
+			return
+		}
+
+		switch r.Method {
+		case http.MethodGet:
+			entry, ok, err := store.Get(key)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+
+				return
+			}
+			if !ok {
+				http.NotFound(w, r)
+
+				return
+			}
+
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(entry)
+		case http.MethodPut:
+			var entry Entry
+			if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxEntrySize)).Decode(&entry); err != nil {
+				http.Error(w, "invalid entry: "+err.Error(), http.StatusBadRequest)
+
+				return
+			}
+
+			if err := store.Put(key, entry); err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+
+				return
+			}
+
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.Header().Set("Allow", "GET, PUT")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+}
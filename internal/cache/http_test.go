@@ -0,0 +1,76 @@
+package cache
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/avito-tech/go-mutesting/internal/models"
+)
+
+func TestHTTP(t *testing.T) {
+	local, err := Open(t.TempDir())
+	require.NoError(t, err)
+
+	server := httptest.NewServer(Handler(local))
+	defer server.Close()
+
+	remote, err := NewHTTP(server.URL + "/")
+	require.NoError(t, err)
+
+	key := Key("mutation")
+
+	_, ok, err := remote.Get(key)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	entry := Entry{Status: models.StatusEscaped, Diff: "@@ -1 +1 @@"}
+	require.NoError(t, remote.Put(key, entry))
+
+	cached, ok, err := remote.Get(key)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, entry, cached)
+
+	// The server stores into its own cache
+	cached, ok, err = local.Get(key)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, entry, cached)
+}
+
+func TestHandlerRejects(t *testing.T) {
+	local, err := Open(t.TempDir())
+	require.NoError(t, err)
+
+	server := httptest.NewServer(Handler(local))
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/../../etc/passwd")
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	req, err := http.NewRequest(http.MethodPut, server.URL+"/"+Key("mutation"), strings.NewReader("{"))
+	require.NoError(t, err)
+	resp, err = http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	req, err = http.NewRequest(http.MethodDelete, server.URL+"/"+Key("mutation"), nil)
+	require.NoError(t, err)
+	resp, err = http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	assert.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+}
+
+func TestNewHTTPInvalidURL(t *testing.T) {
+	_, err := NewHTTP("ftp://example.com")
+	assert.Error(t, err)
+}
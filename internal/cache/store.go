@@ -0,0 +1,93 @@
+package cache
+
+import (
+	"errors"
+	"sync"
+)
+
+// Store stores the results of mutations by their key.
+type Store interface {
+	// Get returns the entry of the given key, the bool tells if there is one.
+	Get(key string) (Entry, bool, error)
+	// Put stores the entry of the given key.
+	Put(key string, entry Entry) error
+}
+
+// chain asks its stores in order and fills the earlier stores with what is found in later ones
+type chain struct {
+	stores []Store
+
+	mu     sync.Mutex
+	failed []bool
+}
+
+// Chain returns a store which reads from the first of the given stores which has an entry, and writes to all of them.
+// Entries which are only found in a later store are copied into the earlier ones, e.g. from a remote into a local cache.
+// A store which returns an error is not used anymore, so an unreachable server is only reported once.
+func Chain(stores ...Store) Store {
+	return &chain{
+		stores: stores,
+		failed: make([]bool, len(stores)),
+	}
+}
+
+// Get implements the Get method of the Store interface
+func (c *chain) Get(key string) (Entry, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var errs []error
+
+	for i, store := range c.stores {
+		if c.failed[i] {
+			continue
+		}
+
+		entry, ok, err := store.Get(key)
+		if err != nil {
+			c.failed[i] = true
+			errs = append(errs, err)
+
+			continue
+		}
+		if !ok {
+			continue
+		}
+
+		for j := 0; j < i; j++ {
+			if c.failed[j] {
+				continue
+			}
+
+			if err := c.stores[j].Put(key, entry); err != nil {
+				c.failed[j] = true
+				errs = append(errs, err)
+			}
+		}
+
+		return entry, true, errors.Join(errs...)
+	}
+
+	return Entry{}, false, errors.Join(errs...)
+}
+
+// Put implements the Put method of the Store interface
+func (c *chain) Put(key string, entry Entry) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var errs []error
+
+	for i, store := range c.stores {
+		if c.failed[i] {
+			continue
+		}
+
+		if err := store.Put(key, entry); err != nil {
+			c.failed[i] = true
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
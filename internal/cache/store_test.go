@@ -0,0 +1,68 @@
+package cache
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/avito-tech/go-mutesting/internal/models"
+)
+
+type failingStore struct {
+	calls int
+}
+
+func (s *failingStore) Get(string) (Entry, bool, error) {
+	s.calls++
+
+	return Entry{}, false, errors.New("unreachable")
+}
+
+func (s *failingStore) Put(string, Entry) error {
+	s.calls++
+
+	return errors.New("unreachable")
+}
+
+func TestChainFillsEarlierStores(t *testing.T) {
+	local, err := Open(t.TempDir())
+	require.NoError(t, err)
+	remote, err := Open(t.TempDir())
+	require.NoError(t, err)
+
+	key := Key("mutation")
+	entry := Entry{Status: models.StatusKilled}
+	require.NoError(t, remote.Put(key, entry))
+
+	cached, ok, err := Chain(local, remote).Get(key)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, entry, cached)
+
+	cached, ok, err = local.Get(key)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, entry, cached)
+}
+
+func TestChainSkipsFailingStores(t *testing.T) {
+	local, err := Open(t.TempDir())
+	require.NoError(t, err)
+	remote := &failingStore{}
+
+	store := Chain(local, remote)
+	key := Key("mutation")
+
+	_, ok, err := store.Get(key)
+	assert.Error(t, err)
+	assert.False(t, ok)
+
+	// The failing store is not asked anymore
+	require.NoError(t, store.Put(key, Entry{Status: models.StatusKilled}))
+	_, ok, err = store.Get(key)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, 1, remote.calls)
+}
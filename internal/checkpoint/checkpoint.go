@@ -0,0 +1,108 @@
+package checkpoint
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"sync"
+
+	"github.com/avito-tech/go-mutesting/internal/models"
+)
+
+// Record is one line of a checkpoint file, the result of one finished mutation
+type Record struct {
+	ID     string        `json:"id"`
+	Mutant models.Mutant `json:"mutant"`
+}
+
+// Load returns the results of the given checkpoint file by the IDs of their mutations.
+// A missing file has no results, and a broken last line is ignored since the run may have been killed while writing it.
+func Load(path string) (map[string]models.Mutant, error) {
+	content, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return map[string]models.Mutant{}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	results := make(map[string]models.Mutant)
+	lines := bytes.Split(bytes.TrimRight(content, "\n"), []byte("\n"))
+
+	for i, line := range lines {
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+
+		var record Record
+		if err := json.Unmarshal(line, &record); err != nil {
+			if i == len(lines)-1 {
+				break
+			}
+
+			return nil, fmt.Errorf("line %d of checkpoint %q is broken: %w", i+1, path, err)
+		}
+
+		results[record.ID] = record.Mutant
+	}
+
+	return results, nil
+}
+
+// Writer appends results to a checkpoint file.
+type Writer struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+// Open opens the given checkpoint file for appending, it is created if it does not exist.
+func Open(path string) (*Writer, error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0666)
+	if err != nil {
+		return nil, err
+	}
+
+	// A line which was cut off by an interrupted run must not swallow the next record
+	if err := dropPartialLine(file); err != nil {
+		_ = file.Close()
+
+		return nil, err
+	}
+
+	return &Writer{file: file}, nil
+}
+
+// Append stores the result of the mutation with the given ID.
+// The file is synced, so the result survives if the run is killed right after.
+func (w *Writer) Append(id string, mutant models.Mutant) error {
+	line, err := json.Marshal(Record{ID: id, Mutant: mutant})
+	if err != nil {
+		return err
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if _, err := w.file.Write(append(line, '\n')); err != nil {
+		return err
+	}
+
+	return w.file.Sync()
+}
+
+// Close closes the checkpoint file.
+func (w *Writer) Close() error {
+	return w.file.Close()
+}
+
+func dropPartialLine(file *os.File) error {
+	content, err := io.ReadAll(io.NewSectionReader(file, 0, 1<<62))
+	if err != nil || len(content) == 0 || content[len(content)-1] == '\n' {
+		return err
+	}
+
+	return file.Truncate(int64(bytes.LastIndexByte(content, '\n') + 1))
+}
@@ -0,0 +1,63 @@
+package checkpoint
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/avito-tech/go-mutesting/internal/models"
+)
+
+func TestCheckpoint(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "checkpoint.jsonl")
+
+	results, err := Load(path)
+	require.NoError(t, err)
+	assert.Empty(t, results)
+
+	w, err := Open(path)
+	require.NoError(t, err)
+	require.NoError(t, w.Append("a", models.Mutant{Status: models.StatusKilled}))
+	require.NoError(t, w.Append("b", models.Mutant{Status: models.StatusEscaped}))
+	require.NoError(t, w.Close())
+
+	results, err = Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]models.Mutant{
+		"a": {Status: models.StatusKilled},
+		"b": {Status: models.StatusEscaped},
+	}, results)
+}
+
+func TestCheckpointInterruptedWrite(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "checkpoint.jsonl")
+	require.NoError(t, os.WriteFile(path, []byte(`{"id":"a","mutant":{"status":"killed"}}`+"\n"+`{"id":"b","mu`), 0666))
+
+	results, err := Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]models.Mutant{"a": {Status: models.StatusKilled}}, results)
+
+	// The cut off line does not swallow the next record
+	w, err := Open(path)
+	require.NoError(t, err)
+	require.NoError(t, w.Append("c", models.Mutant{Status: models.StatusEscaped}))
+	require.NoError(t, w.Close())
+
+	results, err = Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]models.Mutant{
+		"a": {Status: models.StatusKilled},
+		"c": {Status: models.StatusEscaped},
+	}, results)
+}
+
+func TestLoadBrokenLine(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "checkpoint.jsonl")
+	require.NoError(t, os.WriteFile(path, []byte("garbage\n"+`{"id":"a","mutant":{"status":"killed"}}`+"\n"), 0666))
+
+	_, err := Load(path)
+	assert.Error(t, err)
+}
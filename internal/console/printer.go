@@ -3,7 +3,9 @@ package console
 import (
 	"fmt"
 	"github.com/avito-tech/go-mutesting/internal/models"
+	"io"
 	"log"
+	"os"
 	"strings"
 
 	"github.com/fatih/color"
@@ -14,6 +16,7 @@ const (
 	PASS    = "PASS"
 	FAIL    = "FAIL"
 	SKIP    = "SKIP"
+	TIMEOUT = "TIMEOUT"
 	UNKNOWN = "UNKNOWN"
 )
 
@@ -23,39 +26,47 @@ var (
 )
 
 // PrintPass prints in green
-func PrintPass(out string) {
+func PrintPass(w io.Writer, out string) {
 	pass := color.New(color.FgHiWhite, color.BgGreen).SprintfFunc()
 	out = strings.Replace(out, PASS, pass(PASS), 1)
-	fmt.Print(out)
-	color.Blue(frameLine)
+	_, _ = fmt.Fprint(w, out)
+	printFrameLine(w)
 }
 
 // PrintFail prints in red
-func PrintFail(out string) {
+func PrintFail(w io.Writer, out string) {
 	fail := color.New(color.FgHiWhite, color.BgRed).SprintfFunc()
 	out = strings.Replace(out, FAIL, fail(FAIL), 1)
-	fmt.Print(out)
-	color.Blue(frameLine)
+	_, _ = fmt.Fprint(w, out)
+	printFrameLine(w)
 }
 
 // PrintSkip prints in yellow
-func PrintSkip(out string) {
+func PrintSkip(w io.Writer, out string) {
 	skip := color.New(color.FgHiWhite, color.BgYellow).SprintfFunc()
 	out = strings.Replace(out, SKIP, skip(SKIP), 1)
-	fmt.Print(out)
-	color.Blue(frameLine)
+	_, _ = fmt.Fprint(w, out)
+	printFrameLine(w)
+}
+
+// PrintTimeout prints in cyan
+func PrintTimeout(w io.Writer, out string) {
+	timeout := color.New(color.FgHiWhite, color.BgCyan).SprintfFunc()
+	out = strings.Replace(out, TIMEOUT, timeout(TIMEOUT), 1)
+	_, _ = fmt.Fprint(w, out)
+	printFrameLine(w)
 }
 
 // PrintUnknown prints in magenta
-func PrintUnknown(out string) {
+func PrintUnknown(w io.Writer, out string) {
 	unknown := color.New(color.FgHiWhite, color.BgMagenta).SprintfFunc()
 	out = strings.Replace(out, UNKNOWN, unknown(UNKNOWN), 1)
-	fmt.Print(out)
-	color.Blue(frameLine)
+	_, _ = fmt.Fprint(w, out)
+	printFrameLine(w)
 }
 
 // PrintDiff prints colorful diff
-func PrintDiff(diff []byte) {
+func PrintDiff(w io.Writer, diff []byte) {
 	green := color.New(color.FgHiWhite).Add(color.BgGreen)
 	red := color.New(color.FgHiWhite).Add(color.BgRed)
 
@@ -63,41 +74,55 @@ func PrintDiff(diff []byte) {
 	for _, line := range strings.Split(lines, "\n") {
 		switch {
 		case strings.HasPrefix(line, "+++"):
-			_, err := green.Println(line)
+			_, err := green.Fprintln(w, line)
 			if err != nil {
 				log.Printf("Error printing output: %s", err)
 			}
 		case strings.HasPrefix(line, "---"):
-			_, err := red.Println(line)
+			_, err := red.Fprintln(w, line)
 			if err != nil {
 				log.Printf("Error printing output: %s", err)
 			}
 		case strings.HasPrefix(line, "+"):
-			_, err := green.Println(line)
+			_, err := green.Fprintln(w, line)
 			if err != nil {
 				log.Printf("Error printing output: %s", err)
 			}
 		case strings.HasPrefix(line, "-"):
-			_, err := red.Println(line)
+			_, err := red.Fprintln(w, line)
 			if err != nil {
 				log.Printf("Error printing output: %s", err)
 			}
 		default:
-			fmt.Println(line)
+			_, _ = fmt.Fprintln(w, line)
 		}
 	}
 }
 
 // Debug prints formatted debug messages when debug mode is enabled in options.
 func Debug(opts *models.Options, format string, args ...interface{}) {
+	Fdebug(os.Stdout, opts, format, args...)
+}
+
+// Fdebug writes formatted debug messages to w when debug mode is enabled in options.
+func Fdebug(w io.Writer, opts *models.Options, format string, args ...interface{}) {
 	if opts.General.Debug {
-		fmt.Printf(format+"\n", args...)
+		_, _ = fmt.Fprintf(w, format+"\n", args...)
 	}
 }
 
 // Verbose prints formatted messages when either verbose or debug mode is enabled.
 func Verbose(opts *models.Options, format string, args ...interface{}) {
+	Fverbose(os.Stdout, opts, format, args...)
+}
+
+// Fverbose writes formatted messages to w when either verbose or debug mode is enabled.
+func Fverbose(w io.Writer, opts *models.Options, format string, args ...interface{}) {
 	if opts.General.Verbose || opts.General.Debug {
-		fmt.Printf(format+"\n", args...)
+		_, _ = fmt.Fprintf(w, format+"\n", args...)
 	}
 }
+
+func printFrameLine(w io.Writer) {
+	_, _ = color.New(color.FgBlue).Fprintln(w, frameLine)
+}
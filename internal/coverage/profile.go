@@ -0,0 +1,126 @@
+package coverage
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Block a block of statements of a coverage profile
+type Block struct {
+	StartLine int64
+	EndLine   int64
+	Count     int64
+}
+
+// Profile blocks of a coverage profile by file, files are named by their import path, e.g. "github.com/foo/bar/bar.go"
+type Profile map[string][]Block
+
+// ReadProfile reads a coverage profile as written by go test -coverprofile.
+func ReadProfile(path string) (Profile, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+
+	return ParseProfile(f)
+}
+
+// ParseProfile parses a coverage profile as written by go test -coverprofile.
+func ParseProfile(r io.Reader) (Profile, error) {
+	profile := make(Profile)
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" || strings.HasPrefix(line, "mode:") {
+			continue
+		}
+
+		// e.g. "github.com/foo/bar/bar.go:10.2,11.16 2 1"
+		colon := strings.LastIndex(line, ":")
+		fields := strings.Fields(line[colon+1:])
+		if colon < 0 || len(fields) != 3 {
+			return nil, fmt.Errorf("invalid coverage profile line %q", line)
+		}
+
+		start, end, ok := strings.Cut(fields[0], ",")
+		if !ok {
+			return nil, fmt.Errorf("invalid coverage profile line %q", line)
+		}
+
+		startLine, err := parsePosition(start)
+		if err != nil {
+			return nil, fmt.Errorf("invalid coverage profile line %q: %w", line, err)
+		}
+		endLine, err := parsePosition(end)
+		if err != nil {
+			return nil, fmt.Errorf("invalid coverage profile line %q: %w", line, err)
+		}
+		count, err := strconv.ParseInt(fields[2], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid coverage profile line %q: %w", line, err)
+		}
+
+		file := line[:colon]
+		profile[file] = append(profile[file], Block{
+			StartLine: startLine,
+			EndLine:   endLine,
+			Count:     count,
+		})
+	}
+
+	return profile, scanner.Err()
+}
+
+// Covers reports whether one of the given lines of the file is part of an executed block.
+func (p Profile) Covers(file string, lines []int64) bool {
+	for _, block := range p[file] {
+		if block.Count > 0 && block.contains(lines) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Uncovered reports whether the given lines of the file are part of blocks which were never executed.
+// Lines outside of any block, e.g. package level declarations, are unknown to the profile and therefore not uncovered.
+func (p Profile) Uncovered(file string, lines []int64) bool {
+	known := false
+
+	for _, block := range p[file] {
+		if !block.contains(lines) {
+			continue
+		}
+		if block.Count > 0 {
+			return false
+		}
+
+		known = true
+	}
+
+	return known
+}
+
+// contains reports whether one of the given lines is part of the block.
+func (b Block) contains(lines []int64) bool {
+	for _, line := range lines {
+		if line >= b.StartLine && line <= b.EndLine {
+			return true
+		}
+	}
+
+	return false
+}
+
+// parsePosition returns the line of a "line.column" position.
+func parsePosition(position string) (int64, error) {
+	line, _, _ := strings.Cut(position, ".")
+
+	return strconv.ParseInt(line, 10, 64)
+}
@@ -0,0 +1,41 @@
+package coverage
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseProfile(t *testing.T) {
+	profile, err := ParseProfile(strings.NewReader(`mode: set
+example.com/a/a.go:10.2,11.16 2 1
+example.com/a/a.go:14.2,16.3 1 0
+example.com/a/b.go:3.13,5.2 1 4
+`))
+	require.NoError(t, err)
+
+	assert.Equal(t, Profile{
+		"example.com/a/a.go": {{StartLine: 10, EndLine: 11, Count: 1}, {StartLine: 14, EndLine: 16, Count: 0}},
+		"example.com/a/b.go": {{StartLine: 3, EndLine: 5, Count: 4}},
+	}, profile)
+
+	assert.True(t, profile.Covers("example.com/a/a.go", []int64{11}))
+	assert.True(t, profile.Covers("example.com/a/a.go", []int64{1, 10}))
+	assert.False(t, profile.Covers("example.com/a/a.go", []int64{15}))
+	assert.False(t, profile.Covers("example.com/a/c.go", []int64{10}))
+
+	assert.True(t, profile.Uncovered("example.com/a/a.go", []int64{15}))
+	assert.False(t, profile.Uncovered("example.com/a/a.go", []int64{11, 15}))
+	assert.False(t, profile.Uncovered("example.com/a/a.go", []int64{1}))
+	assert.False(t, profile.Uncovered("example.com/a/c.go", []int64{15}))
+}
+
+func TestParseProfileInvalid(t *testing.T) {
+	_, err := ParseProfile(strings.NewReader("mode: set\nexample.com/a/a.go:10.2 2 1\n"))
+	assert.Error(t, err)
+
+	_, err = ParseProfile(strings.NewReader("mode: set\nno colon\n"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,45 @@
+package filter
+
+import (
+	"go/ast"
+	"go/token"
+)
+
+// ChangedLinesFilter is a filter that skips nodes which do not touch any changed line of their file.
+type ChangedLinesFilter struct {
+	// Changed maps absolute file paths to their changed lines
+	Changed map[string][]int64
+
+	fset  *token.FileSet
+	lines []int64
+}
+
+// NewChangedLinesFilter creates and returns a new initialized ChangedLinesFilter.
+func NewChangedLinesFilter(changed map[string][]int64) *ChangedLinesFilter {
+	return &ChangedLinesFilter{Changed: changed}
+}
+
+// Collect looks up the changed lines of the given file
+func (c *ChangedLinesFilter) Collect(_ *ast.File, fset *token.FileSet, fileAbs string) {
+	c.fset = fset
+	c.lines = c.Changed[fileAbs]
+}
+
+// ShouldSkip determines whether a given AST node should be skipped during mutation.
+// Nodes which span several lines, like blocks, are kept if any of their lines changed.
+func (c *ChangedLinesFilter) ShouldSkip(node ast.Node, _ string) bool {
+	if c.fset == nil {
+		return true
+	}
+
+	start := int64(c.fset.Position(node.Pos()).Line)
+	end := int64(c.fset.Position(node.End()).Line)
+
+	for _, line := range c.lines {
+		if line >= start && line <= end {
+			return false
+		}
+	}
+
+	return true
+}
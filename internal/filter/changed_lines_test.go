@@ -0,0 +1,57 @@
+package filter
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChangedLinesFilter(t *testing.T) {
+	code := `package main
+
+func a(x int) int {
+	if x > 0 {
+		return x + 1
+	}
+
+	return x - 1
+}
+`
+
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "main.go", code, 0)
+	require.NoError(t, err)
+
+	f := NewChangedLinesFilter(map[string][]int64{
+		"/src/main.go":  {5},
+		"/src/other.go": {8},
+	})
+	f.Collect(file, fset, "/src/main.go")
+
+	var changed, unchanged []string
+	ast.Inspect(file, func(n ast.Node) bool {
+		if b, ok := n.(*ast.BinaryExpr); ok {
+			if f.ShouldSkip(b, "arithmetic/base") {
+				unchanged = append(unchanged, b.Op.String())
+			} else {
+				changed = append(changed, b.Op.String())
+			}
+		}
+
+		return true
+	})
+
+	assert.Equal(t, []string{"+"}, changed)
+	assert.Equal(t, []string{">", "-"}, unchanged)
+
+	// Blocks are kept if any of their lines changed
+	ifStmt := file.Decls[0].(*ast.FuncDecl).Body.List[0]
+	assert.False(t, f.ShouldSkip(ifStmt, "branch/if"))
+
+	f.Collect(file, fset, "/src/unchanged.go")
+	assert.True(t, f.ShouldSkip(ifStmt, "branch/if"))
+}
@@ -0,0 +1,136 @@
+package gate
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/avito-tech/go-mutesting/internal/models"
+)
+
+// Limits of a gate
+const (
+	// LimitMinMsi the mutation score must not be lower
+	LimitMinMsi = "min_msi"
+	// LimitMaxEscaped the count of escaped mutations must not be higher
+	LimitMaxEscaped = "max_escaped"
+)
+
+// Violation a limit of a gate which was not met
+type Violation struct {
+	// Scope "the total" or the package or directory of the gate
+	Scope  string
+	Limit  string
+	Actual float64
+	Bound  float64
+}
+
+// String describes the violation and by how much the limit was missed
+func (v Violation) String() string {
+	switch v.Limit {
+	case LimitMinMsi:
+		return fmt.Sprintf("The mutation score of %s is %f, %f below the minimum of %f", v.Scope, v.Actual, v.Bound-v.Actual, v.Bound)
+	default:
+		return fmt.Sprintf("The count of escaped mutations of %s is %d, %d above the maximum of %d", v.Scope, int(v.Actual), int(v.Actual-v.Bound), int(v.Bound))
+	}
+}
+
+// Enabled returns if any limit is set
+func Enabled(gates models.Gates) bool {
+	return isSet(gates.Threshold) || len(gates.Packages) > 0 || len(gates.Dirs) > 0
+}
+
+// Check returns the violated limits of the given gates.
+// Package and directory gates are checked against the mutations of their scope, scopes without mutations pass.
+func Check(report models.Report, gates models.Gates) []Violation {
+	violations := check("the total", report, gates.Threshold)
+
+	for _, scoped := range gates.Packages {
+		pattern := scoped.Path
+		scope := filterReport(report, func(mutant models.Mutant) bool {
+			return matchPackage(pattern, mutant.Package)
+		})
+		if scope.TotalCount() == 0 {
+			continue
+		}
+
+		violations = append(violations, check(fmt.Sprintf("package %q", pattern), scope, scoped.Threshold)...)
+	}
+
+	for _, scoped := range gates.Dirs {
+		dir := scoped.Path
+		scope := filterReport(report, func(mutant models.Mutant) bool {
+			return matchDir(dir, mutant.Mutator.OriginalFilePath)
+		})
+		if scope.TotalCount() == 0 {
+			continue
+		}
+
+		violations = append(violations, check(fmt.Sprintf("directory %q", dir), scope, scoped.Threshold)...)
+	}
+
+	return violations
+}
+
+func check(scope string, report models.Report, threshold models.Threshold) []Violation {
+	var violations []Violation
+
+	if threshold.MinMsi != nil {
+		if msi := report.MsiScore(); msi < *threshold.MinMsi {
+			violations = append(violations, Violation{Scope: scope, Limit: LimitMinMsi, Actual: msi, Bound: *threshold.MinMsi})
+		}
+	}
+	if threshold.MaxEscaped != nil {
+		if escaped := report.Stats.EscapedCount; escaped > int64(*threshold.MaxEscaped) {
+			violations = append(violations, Violation{Scope: scope, Limit: LimitMaxEscaped, Actual: float64(escaped), Bound: float64(*threshold.MaxEscaped)})
+		}
+	}
+
+	return violations
+}
+
+func isSet(threshold models.Threshold) bool {
+	return threshold.MinMsi != nil || threshold.MaxEscaped != nil
+}
+
+// filterReport returns a report of the mutants of the given report which match.
+// Skipped mutations are only counted, so they are not part of it.
+func filterReport(report models.Report, match func(mutant models.Mutant) bool) models.Report {
+	filtered := models.Report{}
+
+	for _, mutants := range [][]models.Mutant{
+		report.Killed, report.Escaped, report.Panicked, report.Timeouted,
+		report.BuildFailed, report.VetFailed, report.NotCovered, report.Accepted, report.Errored,
+	} {
+		for _, mutant := range mutants {
+			if match(mutant) {
+				filtered.Add(mutant)
+			}
+		}
+	}
+
+	return filtered
+}
+
+func matchPackage(pattern string, pkg string) bool {
+	if prefix, ok := strings.CutSuffix(pattern, "/..."); ok {
+		return pkg == prefix || strings.HasPrefix(pkg, prefix+"/")
+	}
+
+	return pkg == pattern
+}
+
+func matchDir(dir string, file string) bool {
+	dirAbs, err := filepath.Abs(dir)
+	if err != nil {
+		return false
+	}
+	fileAbs, err := filepath.Abs(file)
+	if err != nil {
+		return false
+	}
+
+	rel, err := filepath.Rel(dirAbs, fileAbs)
+
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
@@ -0,0 +1,64 @@
+package gate
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/avito-tech/go-mutesting/internal/models"
+)
+
+func TestCheck(t *testing.T) {
+	report := models.Report{}
+	for _, mutant := range []models.Mutant{
+		{Package: "example", Status: models.StatusKilled, Mutator: models.Mutator{OriginalFilePath: "example/a.go"}},
+		{Package: "example", Status: models.StatusEscaped, Mutator: models.Mutator{OriginalFilePath: "example/a.go"}},
+		{Package: "example/sub", Status: models.StatusEscaped, Mutator: models.Mutator{OriginalFilePath: "example/sub/b.go"}},
+		{Package: "example/sub", Status: models.StatusEscaped, Mutator: models.Mutator{OriginalFilePath: "example/sub/b.go"}},
+	} {
+		report.Add(mutant)
+	}
+
+	minMsi := 0.5
+	maxEscaped := 1
+
+	assert.Equal(t, []Violation{
+		{Scope: "the total", Limit: LimitMinMsi, Actual: 0.25, Bound: 0.5},
+		{Scope: "the total", Limit: LimitMaxEscaped, Actual: 3, Bound: 1},
+		{Scope: `package "example/sub"`, Limit: LimitMaxEscaped, Actual: 2, Bound: 1},
+		{Scope: `directory "example/sub"`, Limit: LimitMinMsi, Actual: 0, Bound: 0.5},
+	}, Check(report, models.Gates{
+		Threshold: models.Threshold{MinMsi: &minMsi, MaxEscaped: &maxEscaped},
+		Packages: []models.ScopedThreshold{
+			{Path: "example", Threshold: models.Threshold{MinMsi: &minMsi, MaxEscaped: &maxEscaped}},
+			{Path: "example/sub", Threshold: models.Threshold{MaxEscaped: &maxEscaped}},
+			// Scopes without mutations pass
+			{Path: "other/...", Threshold: models.Threshold{MinMsi: &minMsi}},
+		},
+		Dirs: []models.ScopedThreshold{
+			{Path: "example/sub", Threshold: models.Threshold{MinMsi: &minMsi}},
+		},
+	}))
+}
+
+func TestMatchPackage(t *testing.T) {
+	assert.True(t, matchPackage("example", "example"))
+	assert.False(t, matchPackage("example", "example/sub"))
+	assert.True(t, matchPackage("example/...", "example"))
+	assert.True(t, matchPackage("example/...", "example/sub"))
+	assert.False(t, matchPackage("example/...", "examples"))
+}
+
+func TestMatchDir(t *testing.T) {
+	assert.True(t, matchDir("example", "example/a.go"))
+	assert.True(t, matchDir("./example/", "example/sub/b.go"))
+	assert.False(t, matchDir("example/sub", "example/a.go"))
+	assert.False(t, matchDir("example", "examples/a.go"))
+}
+
+func TestViolationString(t *testing.T) {
+	assert.Equal(t, "The mutation score of the total is 0.250000, 0.250000 below the minimum of 0.500000",
+		Violation{Scope: "the total", Limit: LimitMinMsi, Actual: 0.25, Bound: 0.5}.String())
+	assert.Equal(t, `The count of escaped mutations of package "example" is 3, 2 above the maximum of 1`,
+		Violation{Scope: `package "example"`, Limit: LimitMaxEscaped, Actual: 3, Bound: 1}.String())
+}
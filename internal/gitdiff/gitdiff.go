@@ -0,0 +1,46 @@
+package gitdiff
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/avito-tech/go-mutesting/internal/parser"
+)
+
+// ChangedLines returns the lines which were added or modified since the given git ref, as shown by git diff <ref>.
+// Files are keyed by their absolute path. Untracked files are not part of the diff.
+func ChangedLines(ref string) (map[string][]int64, error) {
+	root, err := git("rev-parse", "--show-toplevel")
+	if err != nil {
+		return nil, err
+	}
+
+	diff, err := git("-c", "core.quotePath=false", "diff", "--no-color", "--no-ext-diff", "--unified=0", ref, "--")
+	if err != nil {
+		return nil, err
+	}
+
+	changed := make(map[string][]int64)
+	for file, lines := range parser.ParseGitDiff(diff) {
+		changed[filepath.Join(strings.TrimSpace(string(root)), filepath.FromSlash(file))] = lines
+	}
+
+	return changed, nil
+}
+
+func git(args ...string) ([]byte, error) {
+	var stderr bytes.Buffer
+
+	cmd := exec.Command("git", args...)
+	cmd.Stderr = &stderr
+
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git %s failed: %v: %s", strings.Join(args, " "), err, strings.TrimSpace(stderr.String()))
+	}
+
+	return output, nil
+}
@@ -0,0 +1,148 @@
+package importing
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ModulePackage a package of a module with its imports, as listed by go list
+type ModulePackage struct {
+	ImportPath   string
+	Imports      []string
+	TestImports  []string
+	XTestImports []string
+}
+
+// ModuleRoot returns the root directory of the module which contains the given directory.
+// Unlike go list -m it names a single module in a workspace as well.
+func ModuleRoot(dir string) (string, error) {
+	var stderr bytes.Buffer
+
+	cmd := exec.Command("go", "env", "GOMOD")
+	cmd.Dir = dir
+	cmd.Stderr = &stderr
+
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("%v: %s", err, stderr.String())
+	}
+
+	goMod := strings.TrimSpace(string(output))
+	if goMod == "" || goMod == os.DevNull {
+		return "", fmt.Errorf("%q is not part of a module", dir)
+	}
+
+	return filepath.Dir(goMod), nil
+}
+
+// ListModule lists all packages of the module which contains the given directory, flags are passed on to go list (e.g. -modfile).
+func ListModule(dir string, flags ...string) ([]ModulePackage, error) {
+	moduleDir, err := ModuleRoot(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var stderr bytes.Buffer
+
+	list := exec.Command("go", append(append([]string{"list"}, flags...), "-e", "-json=ImportPath,Imports,TestImports,XTestImports", "./...")...)
+	list.Dir = moduleDir
+	list.Stderr = &stderr
+
+	output, err := list.Output()
+	if err != nil {
+		return nil, fmt.Errorf("%v: %s", err, stderr.String())
+	}
+
+	var packages []ModulePackage
+
+	decoder := json.NewDecoder(bytes.NewReader(output))
+	for {
+		var pkg ModulePackage
+		err := decoder.Decode(&pkg)
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+
+		packages = append(packages, pkg)
+	}
+
+	return packages, nil
+}
+
+// Dependents returns the import paths of the packages whose tests depend on the package with the given import path, the package itself is not part of them.
+// Packages are ordered by their distance in the import graph, those which import the package directly come first.
+// A maxDepth larger than zero limits the distance, and a maxPackages larger than zero limits the count of the returned packages.
+func Dependents(packages []ModulePackage, importPath string, maxDepth int, maxPackages int) []string {
+	// importedBy holds the packages which import a package in their code, testedBy the packages which import it in their tests
+	importedBy := make(map[string][]string)
+	testedBy := make(map[string][]string)
+	for _, pkg := range packages {
+		for _, imported := range pkg.Imports {
+			importedBy[imported] = append(importedBy[imported], pkg.ImportPath)
+		}
+		for _, imported := range append(append([]string(nil), pkg.TestImports...), pkg.XTestImports...) {
+			testedBy[imported] = append(testedBy[imported], pkg.ImportPath)
+		}
+	}
+
+	// listed holds the packages which are part of the dependents, expanded the packages whose importers are visited.
+	// A package which is found through the imports of its tests first is listed, but it is only expanded once it is found through the imports of its code.
+	listed := map[string]struct{}{importPath: {}}
+	expanded := map[string]struct{}{importPath: {}}
+	var dependents []string
+
+	level := []string{importPath}
+	for depth := 1; len(level) > 0 && (maxDepth <= 0 || depth <= maxDepth); depth++ {
+		found := make(map[string]struct{})
+		// Only imports of the code are transitive, imports of tests are not part of the importing package
+		var next []string
+
+		for _, pkg := range level {
+			for _, dependent := range importedBy[pkg] {
+				if _, ok := expanded[dependent]; !ok {
+					expanded[dependent] = struct{}{}
+					next = append(next, dependent)
+				}
+				if _, ok := listed[dependent]; !ok {
+					listed[dependent] = struct{}{}
+					found[dependent] = struct{}{}
+				}
+			}
+			for _, dependent := range testedBy[pkg] {
+				if _, ok := listed[dependent]; !ok {
+					listed[dependent] = struct{}{}
+					found[dependent] = struct{}{}
+				}
+			}
+		}
+
+		sorted := make([]string, 0, len(found))
+		for dependent := range found {
+			sorted = append(sorted, dependent)
+		}
+		sort.Strings(sorted)
+
+		for _, dependent := range sorted {
+			if maxPackages > 0 && len(dependents) == maxPackages {
+				return dependents
+			}
+
+			dependents = append(dependents, dependent)
+		}
+
+		sort.Strings(next)
+		level = next
+	}
+
+	return dependents
+}
@@ -0,0 +1,72 @@
+package importing
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDependents(t *testing.T) {
+	packages := []ModulePackage{
+		{ImportPath: "m/lib"},
+		{ImportPath: "m/service", Imports: []string{"m/lib"}},
+		{ImportPath: "m/api", Imports: []string{"m/service"}, TestImports: []string{"m/testutil"}},
+		{ImportPath: "m/cmd", Imports: []string{"m/api", "m/lib"}},
+		{ImportPath: "m/testutil", Imports: []string{"m/lib"}},
+		{ImportPath: "m/other", XTestImports: []string{"m/service"}},
+		{ImportPath: "m/unrelated"},
+	}
+
+	for _, test := range []struct {
+		maxDepth    int
+		maxPackages int
+		expect      []string
+	}{
+		{0, 0, []string{"m/cmd", "m/service", "m/testutil", "m/api", "m/other"}},
+		{1, 0, []string{"m/cmd", "m/service", "m/testutil"}},
+		{0, 4, []string{"m/cmd", "m/service", "m/testutil", "m/api"}},
+	} {
+		assert.Equal(t, test.expect, Dependents(packages, "m/lib", test.maxDepth, test.maxPackages), "depth %d, packages %d", test.maxDepth, test.maxPackages)
+	}
+
+	// Imports of tests are not transitive
+	assert.Equal(t, []string{"m/api"}, Dependents(packages, "m/testutil", 0, 0))
+
+	assert.Empty(t, Dependents(packages, "m/unrelated", 0, 0))
+
+	// A package whose tests import the package directly and whose code imports it indirectly is still followed
+	packages = []ModulePackage{
+		{ImportPath: "m/lib"},
+		{ImportPath: "m/service", Imports: []string{"m/lib"}},
+		{ImportPath: "m/api", Imports: []string{"m/service"}, XTestImports: []string{"m/lib"}},
+		{ImportPath: "m/cmd", Imports: []string{"m/api"}},
+	}
+	assert.Equal(t, []string{"m/api", "m/service", "m/cmd"}, Dependents(packages, "m/lib", 0, 0))
+}
+
+func TestListModule(t *testing.T) {
+	packages, err := ListModule(".")
+	assert.NoError(t, err)
+
+	var found *ModulePackage
+	for i := range packages {
+		if packages[i].ImportPath == "github.com/avito-tech/go-mutesting/internal/importing" {
+			found = &packages[i]
+		}
+	}
+	if assert.NotNil(t, found) {
+		assert.Contains(t, found.Imports, "go/build")
+		assert.Contains(t, found.TestImports, "github.com/stretchr/testify/assert")
+	}
+}
+
+func TestModuleRoot(t *testing.T) {
+	root, err := ModuleRoot(filepath.Join("..", "..", "testdata", "scope", "lib"))
+	assert.NoError(t, err)
+	assert.FileExists(t, filepath.Join(root, "go.mod"))
+	assert.Equal(t, "scope", filepath.Base(root))
+
+	_, err = ModuleRoot(t.TempDir())
+	assert.Error(t, err)
+}
@@ -0,0 +1,259 @@
+package journal
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"syscall"
+)
+
+// Extension is the file extension of journal files.
+const Extension = ".journal"
+
+// DisplacedSuffix is appended to the path of an original file while a mutation is swapped into its place.
+const DisplacedSuffix = ".tmp"
+
+// Entry describes one original source file which is displaced by a mutation.
+type Entry struct {
+	Original  string `json:"original"`
+	Displaced string `json:"displaced"`
+	Checksum  string `json:"checksum"`
+}
+
+// Journal records all in-flight file swaps of one run, so they can be restored if the run is interrupted.
+type Journal struct {
+	path string
+
+	mu      sync.Mutex
+	content content
+}
+
+type content struct {
+	PID     int     `json:"pid"`
+	Entries []Entry `json:"entries"`
+}
+
+// PathFor returns the journal path which belongs to the given tmp dir of a run.
+func PathFor(tmpDir string) string {
+	return filepath.Clean(tmpDir) + Extension
+}
+
+// Create creates a new empty journal at path.
+func Create(path string) (*Journal, error) {
+	j := &Journal{
+		path: path,
+		content: content{
+			PID:     os.Getpid(),
+			Entries: []Entry{},
+		},
+	}
+
+	if err := j.write(); err != nil {
+		return nil, err
+	}
+
+	return j, nil
+}
+
+// Begin records that original is about to be displaced by a mutation.
+func (j *Journal) Begin(original string) (Entry, error) {
+	originalAbs, err := filepath.Abs(original)
+	if err != nil {
+		return Entry{}, fmt.Errorf("could not absolute the file path of %q: %w", original, err)
+	}
+
+	checksum, err := Checksum(originalAbs)
+	if err != nil {
+		return Entry{}, err
+	}
+
+	e := Entry{
+		Original:  originalAbs,
+		Displaced: originalAbs + DisplacedSuffix,
+		Checksum:  checksum,
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	j.content.Entries = append(j.content.Entries, e)
+
+	return e, j.write()
+}
+
+// End restores the original of the given entry if it is still displaced and removes the entry from the journal.
+func (j *Journal) End(e Entry) error {
+	restoreErr := Restore(e)
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	for i := range j.content.Entries {
+		if j.content.Entries[i] == e {
+			j.content.Entries = append(j.content.Entries[:i], j.content.Entries[i+1:]...)
+
+			break
+		}
+	}
+
+	if restoreErr != nil {
+		return restoreErr
+	}
+
+	return j.write()
+}
+
+// RestoreAll restores every original which is recorded in the journal.
+func (j *Journal) RestoreAll() error {
+	j.mu.Lock()
+	entries := append([]Entry(nil), j.content.Entries...)
+	j.mu.Unlock()
+
+	var errs []error
+	for _, e := range entries {
+		if err := j.End(e); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Close removes the journal file. It fails if there are still displaced files recorded.
+func (j *Journal) Close() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if len(j.content.Entries) > 0 {
+		return fmt.Errorf("journal %q still has %d displaced files", j.path, len(j.content.Entries))
+	}
+
+	return os.Remove(j.path)
+}
+
+func (j *Journal) write() error {
+	data, err := json.Marshal(j.content)
+	if err != nil {
+		return err
+	}
+
+	// Write the journal atomically so an interruption never leaves a truncated journal behind
+	tmp := j.path + ".new"
+	if err := os.WriteFile(tmp, data, 0666); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, j.path)
+}
+
+// Load reads the entries of the journal at path and the PID of the run which wrote it.
+func Load(path string) (entries []Entry, pid int, err error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var c content
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, 0, fmt.Errorf("could not parse journal %q: %w", path, err)
+	}
+
+	return c.Entries, c.PID, nil
+}
+
+// Find returns all journal files in dir.
+func Find(dir string) ([]string, error) {
+	return filepath.Glob(filepath.Join(dir, "go-mutesting-*"+Extension))
+}
+
+// Abandoned returns all journal files in dir whose run is not alive anymore.
+func Abandoned(dir string) ([]string, error) {
+	paths, err := Find(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var abandoned []string
+	for _, path := range paths {
+		_, pid, err := Load(path)
+		if err != nil {
+			return nil, err
+		}
+
+		if !alive(pid) {
+			abandoned = append(abandoned, path)
+		}
+	}
+
+	return abandoned, nil
+}
+
+// IsDisplaced reports whether the original of the entry is still displaced.
+func IsDisplaced(e Entry) bool {
+	_, err := os.Stat(e.Displaced)
+
+	return err == nil
+}
+
+// Restore moves the displaced original of the entry back into its place and verifies it against the recorded checksum.
+// Nothing is done if the original is already in place.
+func Restore(e Entry) error {
+	if !IsDisplaced(e) {
+		checksum, err := Checksum(e.Original)
+		if err != nil {
+			return fmt.Errorf("%q is neither displaced nor in place: %w", e.Original, err)
+		}
+		if checksum != e.Checksum {
+			return fmt.Errorf("%q does not match its recorded checksum", e.Original)
+		}
+
+		return nil
+	}
+
+	checksum, err := Checksum(e.Displaced)
+	if err != nil {
+		return err
+	}
+	if checksum != e.Checksum {
+		return fmt.Errorf("displaced original %q does not match its recorded checksum, it is left untouched", e.Displaced)
+	}
+
+	return os.Rename(e.Displaced, e.Original)
+}
+
+// Checksum returns the hex encoded SHA-256 checksum of the given file.
+func Checksum(file string) (string, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func alive(pid int) bool {
+	if pid <= 0 {
+		return false
+	}
+
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+
+	return p.Signal(syscall.Signal(0)) == nil
+}
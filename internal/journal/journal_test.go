@@ -0,0 +1,106 @@
+package journal
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJournalBeginEnd(t *testing.T) {
+	dir := t.TempDir()
+	original := filepath.Join(dir, "original.go")
+	require.NoError(t, os.WriteFile(original, []byte("package original\n"), 0666))
+
+	j, err := Create(PathFor(filepath.Join(dir, "go-mutesting-1")))
+	require.NoError(t, err)
+
+	e, err := j.Begin(original)
+	require.NoError(t, err)
+	assert.Equal(t, original+DisplacedSuffix, e.Displaced)
+
+	entries, pid, err := Load(PathFor(filepath.Join(dir, "go-mutesting-1")))
+	require.NoError(t, err)
+	assert.Equal(t, os.Getpid(), pid)
+	assert.Equal(t, []Entry{e}, entries)
+
+	// Simulate an exec command which swaps the mutation in but does not clean up
+	require.NoError(t, os.Rename(original, e.Displaced))
+	require.NoError(t, os.WriteFile(original, []byte("package mutated\n"), 0666))
+	assert.Error(t, j.Close())
+
+	require.NoError(t, j.End(e))
+	assertContent(t, original, "package original\n")
+	assert.False(t, IsDisplaced(e))
+
+	require.NoError(t, j.Close())
+	_, err = os.Stat(PathFor(filepath.Join(dir, "go-mutesting-1")))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestRestore(t *testing.T) {
+	dir := t.TempDir()
+	original := filepath.Join(dir, "original.go")
+	require.NoError(t, os.WriteFile(original, []byte("package original\n"), 0666))
+	checksum, err := Checksum(original)
+	require.NoError(t, err)
+
+	e := Entry{
+		Original:  original,
+		Displaced: original + DisplacedSuffix,
+		Checksum:  checksum,
+	}
+
+	t.Run("in place", func(t *testing.T) {
+		assert.NoError(t, Restore(e))
+		assertContent(t, original, "package original\n")
+	})
+
+	t.Run("displaced", func(t *testing.T) {
+		require.NoError(t, os.Rename(original, e.Displaced))
+		require.NoError(t, os.WriteFile(original, []byte("package mutated\n"), 0666))
+
+		assert.NoError(t, Restore(e))
+		assertContent(t, original, "package original\n")
+		assert.False(t, IsDisplaced(e))
+	})
+
+	t.Run("displaced file was modified", func(t *testing.T) {
+		require.NoError(t, os.Rename(original, e.Displaced))
+		require.NoError(t, os.WriteFile(e.Displaced, []byte("package other\n"), 0666))
+		require.NoError(t, os.WriteFile(original, []byte("package mutated\n"), 0666))
+
+		assert.Error(t, Restore(e))
+		assertContent(t, original, "package mutated\n")
+		assert.True(t, IsDisplaced(e))
+	})
+}
+
+func TestAbandoned(t *testing.T) {
+	dir := t.TempDir()
+
+	j, err := Create(PathFor(filepath.Join(dir, "go-mutesting-alive")))
+	require.NoError(t, err)
+	defer func() {
+		_ = j.Close()
+	}()
+
+	abandoned := PathFor(filepath.Join(dir, "go-mutesting-abandoned"))
+	require.NoError(t, os.WriteFile(abandoned, []byte(`{"pid":0,"entries":[]}`), 0666))
+
+	found, err := Find(dir)
+	require.NoError(t, err)
+	assert.Len(t, found, 2)
+
+	found, err = Abandoned(dir)
+	require.NoError(t, err)
+	assert.Equal(t, []string{abandoned}, found)
+}
+
+func assertContent(t *testing.T, file string, expected string) {
+	content, err := os.ReadFile(file)
+	require.NoError(t, err)
+	assert.Equal(t, expected, string(content))
+}
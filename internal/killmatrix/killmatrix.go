@@ -0,0 +1,178 @@
+package killmatrix
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/avito-tech/go-mutesting/internal/models"
+)
+
+// Build builds the kill matrix of the mutants of the given report.
+// Only top-level tests are part of the matrix since subtests cannot be selected or removed on their own.
+func Build(report models.Report) *models.KillMatrix {
+	index := make(map[models.Test]int)
+	var tests []models.Test
+
+	addTest := func(test models.Test) {
+		if strings.Contains(test.Name, "/") {
+			return
+		}
+		if _, ok := index[test]; ok {
+			return
+		}
+
+		index[test] = -1
+		tests = append(tests, test)
+	}
+
+	for _, baseline := range report.Baselines {
+		for _, test := range baseline.Tests {
+			addTest(test)
+		}
+	}
+
+	var mutants []models.Mutant
+	for _, category := range [][]models.Mutant{report.Killed, report.Panicked, report.Timeouted, report.Escaped} {
+		mutants = append(mutants, category...)
+	}
+
+	for _, mutant := range mutants {
+		for _, test := range mutant.KilledBy {
+			addTest(test)
+		}
+	}
+
+	sort.Slice(tests, func(i, j int) bool {
+		if tests[i].Package != tests[j].Package {
+			return tests[i].Package < tests[j].Package
+		}
+
+		return tests[i].Name < tests[j].Name
+	})
+	for i, test := range tests {
+		index[test] = i
+	}
+
+	matrix := &models.KillMatrix{
+		Tests:   tests,
+		Mutants: make([]models.KillMatrixRow, 0, len(mutants)),
+	}
+
+	// kills holds the set of killed mutants of every test
+	kills := make([]map[int]struct{}, len(tests))
+	for i := range kills {
+		kills[i] = make(map[int]struct{})
+	}
+
+	for m, mutant := range mutants {
+		row := models.KillMatrixRow{
+			ID:                mutant.ID,
+			Checksum:          mutant.Checksum,
+			MutatorName:       mutant.Mutator.MutatorName,
+			OriginalFilePath:  mutant.Mutator.OriginalFilePath,
+			OriginalStartLine: mutant.Mutator.OriginalStartLine,
+			KilledBy:          []int{},
+		}
+
+		for _, test := range mutant.KilledBy {
+			i, ok := index[test]
+			if !ok || i < 0 {
+				continue
+			}
+			if _, ok := kills[i][m]; ok {
+				continue
+			}
+
+			kills[i][m] = struct{}{}
+			row.KilledBy = append(row.KilledBy, i)
+		}
+
+		sort.Ints(row.KilledBy)
+		matrix.Mutants = append(matrix.Mutants, row)
+	}
+
+	matrix.RedundantTests = redundantTests(tests, kills)
+	matrix.MinimalTests = minimalTests(tests, kills)
+
+	return matrix
+}
+
+// redundantTests returns the tests whose killed mutants are a subset of the ones of another test.
+// Of tests which kill exactly the same mutants the first one is kept.
+func redundantTests(tests []models.Test, kills []map[int]struct{}) []models.RedundantTest {
+	redundant := []models.RedundantTest{}
+
+	for i := range tests {
+		best := -1
+
+		for j := range tests {
+			if i == j || !isSubset(kills[i], kills[j]) {
+				continue
+			}
+			if len(kills[i]) == len(kills[j]) && j > i {
+				// Same mutants, only the later test is redundant
+				continue
+			}
+
+			if best < 0 || len(kills[j]) > len(kills[best]) {
+				best = j
+			}
+		}
+
+		if best >= 0 {
+			redundant = append(redundant, models.RedundantTest{
+				Test:       tests[i],
+				SubsumedBy: tests[best],
+			})
+		}
+	}
+
+	return redundant
+}
+
+// minimalTests returns a small set of tests which kills every mutant that is killed by any test.
+// Finding the smallest set is NP-hard, so tests are picked greedily by the number of mutants they add.
+func minimalTests(tests []models.Test, kills []map[int]struct{}) []models.Test {
+	minimal := []models.Test{}
+	killed := make(map[int]struct{})
+
+	for {
+		best, bestCount := -1, 0
+
+		for i := range tests {
+			count := 0
+			for m := range kills[i] {
+				if _, ok := killed[m]; !ok {
+					count++
+				}
+			}
+
+			if count > bestCount {
+				best, bestCount = i, count
+			}
+		}
+
+		if best < 0 {
+			return minimal
+		}
+
+		minimal = append(minimal, tests[best])
+		for m := range kills[best] {
+			killed[m] = struct{}{}
+		}
+	}
+}
+
+func isSubset(a, b map[int]struct{}) bool {
+	if len(a) > len(b) {
+		return false
+	}
+
+	for m := range a {
+		if _, ok := b[m]; !ok {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,65 @@
+package killmatrix
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/avito-tech/go-mutesting/internal/models"
+)
+
+func TestBuild(t *testing.T) {
+	a := models.Test{Package: "example", Name: "TestA"}
+	b := models.Test{Package: "example", Name: "TestB"}
+	c := models.Test{Package: "example", Name: "TestC"}
+	d := models.Test{Package: "example", Name: "TestD"}
+	e := models.Test{Package: "example", Name: "TestE"}
+	sub := models.Test{Package: "example", Name: "TestA/sub"}
+
+	report := models.Report{
+		Killed: []models.Mutant{
+			{Checksum: "1", KilledBy: []models.Test{a, sub, b}},
+			{Checksum: "2", KilledBy: []models.Test{a, c}},
+			{Checksum: "3", KilledBy: []models.Test{c, d}},
+		},
+		Panicked: []models.Mutant{
+			{Checksum: "4", KilledBy: []models.Test{d}},
+		},
+		Escaped: []models.Mutant{
+			{Checksum: "5"},
+		},
+		Baselines: []models.Baseline{
+			{Package: "example", Passed: true, Tests: []models.Test{a, sub, b, c, d, e}},
+		},
+	}
+
+	matrix := Build(report)
+
+	assert.Equal(t, []models.Test{a, b, c, d, e}, matrix.Tests)
+	assert.Equal(t, []models.KillMatrixRow{
+		{Checksum: "1", KilledBy: []int{0, 1}},
+		{Checksum: "2", KilledBy: []int{0, 2}},
+		{Checksum: "3", KilledBy: []int{2, 3}},
+		{Checksum: "4", KilledBy: []int{3}},
+		{Checksum: "5", KilledBy: []int{}},
+	}, matrix.Mutants)
+	assert.Equal(t, []models.RedundantTest{
+		{Test: b, SubsumedBy: a},
+		{Test: e, SubsumedBy: a},
+	}, matrix.RedundantTests)
+	assert.Equal(t, []models.Test{a, d}, matrix.MinimalTests)
+}
+
+func TestBuildSameKills(t *testing.T) {
+	a := models.Test{Package: "example", Name: "TestA"}
+	b := models.Test{Package: "example", Name: "TestB"}
+
+	matrix := Build(models.Report{
+		Killed: []models.Mutant{
+			{KilledBy: []models.Test{a, b}},
+		},
+	})
+
+	assert.Equal(t, []models.RedundantTest{{Test: b, SubsumedBy: a}}, matrix.RedundantTests)
+	assert.Equal(t, []models.Test{a}, matrix.MinimalTests)
+}
@@ -1,5 +1,7 @@
 package models
 
+import "time"
+
 // Options Main config structure
 type Options struct {
 	General struct {
@@ -12,9 +14,11 @@ type Options struct {
 	} `group:"General options"`
 
 	Files struct {
-		Blacklist []string `long:"blacklist" description:"List of MD5 checksums of mutations which should be ignored. Each checksum must end with a new line character."`
-		ListFiles bool     `long:"list-files" description:"List found files"`
-		PrintAST  bool     `long:"print-ast" description:"Print the ASTs of all given files and exit"`
+		Blacklist        []string `long:"blacklist" description:"List of IDs of mutations which should be ignored. Each ID must end with a new line character. MD5 checksums of mutated files are accepted as well."`
+		MigrateBlacklist bool     `long:"migrate-blacklist" description:"Print the --blacklist files with the checksums of mutated files replaced by the IDs of their mutations and exit"`
+		Accepted         string   `long:"accepted" description:"YAML or JSON file of surviving mutations which are accepted with a reason, they are reported as accepted and do not count against the score"`
+		ListFiles        bool     `long:"list-files" description:"List found files"`
+		PrintAST         bool     `long:"print-ast" description:"Print the ASTs of all given files and exit"`
 	} `group:"File options"`
 
 	Mutator struct {
@@ -23,19 +27,46 @@ type Options struct {
 	} `group:"Mutator options"`
 
 	Filter struct {
-		Match string `long:"match" description:"Only functions are mutated that confirm to the arguments regex"`
+		Match        string `long:"match" description:"Only functions are mutated that confirm to the arguments regex"`
+		ChangedSince string `long:"changed-since" description:"Only code on lines which were added or modified since the given git ref (as shown by git diff <ref>) is mutated"`
+		RerunEscaped string `long:"rerun-escaped" description:"Only test the mutations which escaped in the given JSON report again and report which of them are killed now, still escape or are stale since their code changed"`
+		Sample       string `long:"sample" description:"Only test a random sample of the mutations, given as a fraction (e.g. 0.1) or a count (e.g. 200), the report estimates the mutation score of all of them"`
+		Seed         *int64 `long:"seed" description:"Seed of the random choices of --sample and --time-budget, the same seed picks the same mutations (by default a new seed is picked and reported)"`
+		Shard        string `long:"shard" description:"Only test shard k of n of all mutations, given as k/n (e.g. 2/4 on the second of four machines), reports of all shards can be combined with \"go-mutesting merge\""`
 	} `group:"Filter options"`
 
 	Exec struct {
-		Exec    string `long:"exec" description:"Execute this command for every mutation (by default the built-in exec command is used)"`
-		NoExec  bool   `long:"no-exec" description:"Skip the built-in exec command and just generate the mutations"`
-		Timeout uint   `long:"exec-timeout" description:"Sets a timeout for the command execution (in seconds)" default:"10"`
+		Exec          string        `long:"exec" description:"Execute this command for every mutation (by default the built-in exec command is used)"`
+		NoExec        bool          `long:"no-exec" description:"Skip the built-in exec command and just generate the mutations"`
+		Timeout       uint          `long:"exec-timeout" description:"Sets a timeout for the command execution including compilation (in seconds), by default it is derived from the duration of the tests on the original code"`
+		TimeoutFactor float64       `long:"exec-timeout-factor" description:"Multiple of the duration of the tests on the original code which is used as timeout if --exec-timeout is not set (at least 10 seconds)" default:"3"`
+		Jobs          int           `long:"jobs" description:"Number of mutations which are executed in parallel (0 uses one worker per CPU)" default:"1"`
+		Schemata      bool          `long:"schemata" description:"Compile all mutations of a package into one test binary which is run once per mutation, mutations which cannot be compiled this way are tested one by one"`
+		Checkpoint    string        `long:"checkpoint" description:"Append the result of every finished mutation to this file, so an interrupted run can be resumed"`
+		TimeBudget    time.Duration `long:"time-budget" description:"Stop testing mutations once the run took this long (e.g. 15m), mutations are tested in the order of their priority and the report estimates the mutation score of all of them"`
+		Resume        string        `long:"resume" description:"Resume an interrupted run from its checkpoint file, mutations with a stored result are not tested again (new results are appended to the same file unless --checkpoint is set)"`
 	} `group:"Exec options"`
 
 	Test struct {
-		Recursive bool `long:"test-recursive" description:"Defines if the executer should test recursively"`
+		Recursive              bool     `long:"test-recursive" description:"Defines if the executer should test recursively"`
+		NoBaseline             bool     `long:"no-baseline" description:"Do not test the original code of every package before its mutations are tested"`
+		ExcludeFailingPackages bool     `long:"exclude-failing-packages" description:"Skip the mutations of packages whose tests do not pass on the original code instead of aborting"`
+		CoverProfile           string   `long:"coverprofile" description:"Coverage profile of the tests on the original code, mutations of code which no test executes are not tested (by default the profile is recorded by the baseline run of the built-in exec command)"`
+		SelectTests            bool     `long:"select-tests" description:"Run only the tests which execute the mutated lines, based on the coverage of every test on the original code"`
+		Scope                  string   `long:"test-scope" description:"Tests which test every mutation: the tests of its package, or also the tests of all packages of the module which import it directly or indirectly" choice:"package" choice:"dependents" default:"package"`
+		ScopeDepth             int      `long:"test-scope-depth" description:"Only run the tests of dependent packages up to this distance in the import graph with --test-scope dependents, 1 only covers packages which import the mutated package directly (0 means no limit)"`
+		ScopeMaxPackages       int      `long:"test-scope-max-packages" description:"Only run the tests of this many dependent packages, the closest ones first, with --test-scope dependents (0 means no limit)"`
+		Consumers              []string `long:"consumer" description:"Directory of a module which uses the mutated module, mutations which survive the tests of the mutated module are tested with the tests of its packages which import the mutated package (can be given multiple times)"`
+		KillMatrix             bool     `long:"kill-matrix" description:"Run all tests for every mutation instead of stopping at the first failing test and report redundant tests and a minimal test set"`
 	} `group:"Test options"`
 
+	Cache struct {
+		Dir string `long:"cache-dir" description:"Directory of the result cache (e.g. .go-mutesting-cache), mutations whose source, tests and dependencies did not change reuse their cached result"`
+		URL string `long:"cache-url" description:"URL of a shared result cache, e.g. of \"go-mutesting cache-server\" (used after --cache-dir if both are set)"`
+	} `group:"Cache options"`
+
+	Gate GateOptions `group:"Gate options"`
+
 	Remaining struct {
 		Targets []string `description:"Packages, directories and files even with patterns (by default the current directory)"`
 	} `positional-args:"true" required:"true"`
@@ -47,5 +78,109 @@ type Options struct {
 		HTMLOutput           bool     `yaml:"html_output"`
 		SilentMode           bool     `yaml:"silent_mode"`
 		ExcludeDirs          []string `yaml:"exclude_dirs"`
+		Gates                Gates    `yaml:"gates"`
+		Stages               []Stage  `yaml:"stages"`
 	}
 }
+
+// Stage step of tiered test execution, a mutation is only tested by a stage if it survived all stages before
+type Stage struct {
+	Name string `yaml:"name"`
+	// Exec command which tests the mutation like --exec, by default the exec command of the run is used
+	Exec string `yaml:"exec"`
+	// Flags additional arguments of go test for the built-in exec command, e.g. -count=1
+	Flags []string `yaml:"flags"`
+	// Tags build tags of go test for the built-in exec command
+	Tags []string `yaml:"tags"`
+	// Timeout of the stage, by default the timeout of the package is used
+	Timeout time.Duration `yaml:"timeout"`
+}
+
+// GateOptions thresholds of the whole run which are given as arguments
+type GateOptions struct {
+	MinMsi     *float64 `long:"min-msi" description:"Fail with exit code 4 if the mutation score is below this value (between 0 and 1), overrides min_msi of the gates in the config file"`
+	MaxEscaped *int     `long:"max-escaped" description:"Fail with exit code 4 if more mutations escaped, overrides max_escaped of the gates in the config file"`
+}
+
+// Gates thresholds a run has to meet, as a whole and for single packages or directories
+type Gates struct {
+	Threshold `yaml:",inline"`
+	// Packages thresholds of packages, a path ending with /... covers its sub-packages as well
+	Packages []ScopedThreshold `yaml:"packages"`
+	// Dirs thresholds of directories including their sub-directories
+	Dirs []ScopedThreshold `yaml:"dirs"`
+}
+
+// Threshold limits of one gate, unset limits are not checked
+type Threshold struct {
+	MinMsi     *float64 `yaml:"min_msi"`
+	MaxEscaped *int     `yaml:"max_escaped"`
+}
+
+// ScopedThreshold limits of the mutations of one package or directory
+type ScopedThreshold struct {
+	Path      string `yaml:"path"`
+	Threshold `yaml:",inline"`
+}
+
+// RestoreOptions config structure of the restore command
+type RestoreOptions struct {
+	General struct {
+		Help bool `long:"help" description:"Show this help message"`
+	} `group:"General options"`
+
+	Remaining struct {
+		Journals []string `description:"Journal files of interrupted runs (by default all abandoned journals in the temporary directory)"`
+	} `positional-args:"true"`
+}
+
+// MergeOptions config structure of the merge command
+type MergeOptions struct {
+	General struct {
+		Help       bool `long:"help" description:"Show this help message"`
+		HTMLOutput bool `long:"html-output" description:"Generates a go-mutesting-report.html file of the merged report"`
+	} `group:"General options"`
+
+	Gate GateOptions `group:"Gate options"`
+
+	Remaining struct {
+		Reports []string `description:"JSON reports to merge, e.g. of all shards of a run"`
+	} `positional-args:"true" required:"true"`
+}
+
+// AcceptOptions config structure of the accept command
+type AcceptOptions struct {
+	General struct {
+		Help bool `long:"help" description:"Show this help message"`
+	} `group:"General options"`
+
+	Files struct {
+		Report   string `long:"report" description:"JSON report of the run whose surviving mutations are accepted" default:"report.json"`
+		Accepted string `long:"accepted" description:"YAML or JSON file of accepted mutations to append to, it is created if it does not exist" required:"true"`
+	} `group:"File options"`
+
+	Filter struct {
+		File     []string `long:"file" description:"Only accept mutations of files matching the glob pattern, can be given more than once"`
+		Function string   `long:"function" description:"Only accept mutations of functions matching the regex, methods are named Type.Method"`
+		Mutator  []string `long:"mutator" description:"Only accept mutations of the mutator by its name or using * as a suffix pattern, can be given more than once"`
+	} `group:"Filter options"`
+
+	Acceptance struct {
+		Reason  string `long:"reason" description:"Why the mutations are accepted" required:"true"`
+		Owner   string `long:"owner" description:"Who is responsible for the accepted mutations"`
+		Expires string `long:"expires" description:"Date (YYYY-MM-DD) on which the acceptance expires and the mutations count against the score again"`
+	} `group:"Acceptance options"`
+}
+
+// CacheServerOptions config structure of the cache-server command
+type CacheServerOptions struct {
+	General struct {
+		Help    bool `long:"help" description:"Show this help message"`
+		Verbose bool `long:"verbose" description:"Log every request"`
+	} `group:"General options"`
+
+	Server struct {
+		Listen string `long:"listen" description:"Address to listen on" default:":8080"`
+		Dir    string `long:"dir" description:"Directory of the stored results" default:".go-mutesting-cache"`
+	} `group:"Server options"`
+}
@@ -13,6 +13,105 @@ type Report struct {
 	Timeouted []Mutant `json:"timeouted"`
 	Killed    []Mutant `json:"killed"`
 	Errored   []Mutant `json:"errored"`
+
+	BuildFailed []Mutant `json:"buildFailed,omitempty"`
+	VetFailed   []Mutant `json:"vetFailed,omitempty"`
+	Panicked    []Mutant `json:"panicked,omitempty"`
+	NotCovered  []Mutant `json:"notCovered,omitempty"`
+	Accepted    []Mutant `json:"accepted,omitempty"`
+
+	Baselines []Baseline `json:"baselines,omitempty"`
+
+	KillMatrix *KillMatrix `json:"killMatrix,omitempty"`
+
+	Rerun *RerunDelta `json:"rerun,omitempty"`
+
+	Sampling *Sampling `json:"sampling,omitempty"`
+}
+
+// Sampling which share of the mutations was tested, it is only recorded with --sample or --time-budget
+type Sampling struct {
+	// Sample fraction or count of the mutations which were picked, see --sample
+	Sample string `json:"sample,omitempty"`
+	Seed   int64  `json:"seed"`
+	// TimeBudget duration after which no more mutations were tested, see --time-budget
+	TimeBudget string `json:"timeBudget,omitempty"`
+	// Population count of the mutations which the sample was picked from
+	Population int `json:"population"`
+	// Sampled count of the picked mutations
+	Sampled int `json:"sampled"`
+	// OverBudget count of the picked mutations which were not tested since the time budget ran out
+	OverBudget int `json:"overBudget,omitempty"`
+	// Prioritized count of the picked mutations which were tested ahead of the random order of the time budget, cached ones and ones of changed lines.
+	// They are no fair sample, so MsiLow and MsiHigh do not cover them
+	Prioritized int `json:"prioritized,omitempty"`
+	// Detected and Counted count the detected mutations and the mutations which count for the score among the tested ones which were not prioritized
+	Detected int64 `json:"detected"`
+	Counted  int64 `json:"counted"`
+	// ConfidenceLevel of the interval of MsiLow and MsiHigh
+	ConfidenceLevel float64 `json:"confidenceLevel"`
+	// MsiLow and MsiHigh bound the mutation score of the whole population, which is estimated from the tested mutations
+	MsiLow  float64 `json:"msiLow"`
+	MsiHigh float64 `json:"msiHigh"`
+}
+
+// RerunDelta outcome of testing the escaped mutants of an earlier report again, it is only recorded with --rerun-escaped
+type RerunDelta struct {
+	// Report path of the earlier report
+	Report string `json:"report"`
+	// Total count of the escaped mutants of the earlier report
+	Total int `json:"total"`
+	// Killed IDs of the mutants which are detected now
+	Killed []string `json:"killed"`
+	// Escaped IDs of the mutants which still escape
+	Escaped []string `json:"escaped"`
+	// Stale mutants of the earlier report whose code changed since, so they were not tested again
+	Stale []Mutant `json:"stale"`
+}
+
+// KillMatrix which tests killed which mutants, it is only recorded with --kill-matrix
+type KillMatrix struct {
+	Tests   []Test          `json:"tests"`
+	Mutants []KillMatrixRow `json:"mutants"`
+	// RedundantTests tests whose killed mutants are all killed by another test as well
+	RedundantTests []RedundantTest `json:"redundantTests"`
+	// MinimalTests a small set of tests which kills all mutants that are killed by any test
+	MinimalTests []Test `json:"minimalTests"`
+}
+
+// KillMatrixRow tests which killed one mutant
+type KillMatrixRow struct {
+	ID                string `json:"id,omitempty"`
+	Checksum          string `json:"checksum"`
+	MutatorName       string `json:"mutatorName"`
+	OriginalFilePath  string `json:"originalFilePath"`
+	OriginalStartLine int64  `json:"originalStartLine"`
+	// KilledBy indexes into KillMatrix.Tests
+	KilledBy []int `json:"killedBy"`
+}
+
+// RedundantTest test whose killed mutants are a subset of the ones of another test
+type RedundantTest struct {
+	Test       Test `json:"test"`
+	SubsumedBy Test `json:"subsumedBy"`
+}
+
+// Baseline result of the tests on the original code of one package
+type Baseline struct {
+	Package string `json:"package"`
+	// Duration of the tests in seconds
+	Duration float64 `json:"duration"`
+	Passed   bool    `json:"passed"`
+	// Stage of tiered test execution whose tests do not pass on the original code
+	Stage string `json:"stage,omitempty"`
+	// Tests which ran on the original code
+	Tests []Test `json:"tests,omitempty"`
+}
+
+// Test identifies a test function or subtest of a package
+type Test struct {
+	Package string `json:"package"`
+	Name    string `json:"name"`
 }
 
 // Stats There is stats for mutations
@@ -25,16 +124,85 @@ type Stats struct {
 	SkippedCount         int64   `json:"skippedCount"`
 	TimeOutCount         int64   `json:"timeOutCount"`
 	Msi                  float64 `json:"msi"`
-	MutationCodeCoverage int64   `json:"mutationCodeCoverage"`
+	MutationCodeCoverage float64 `json:"mutationCodeCoverage"`
 	CoveredCodeMsi       float64 `json:"coveredCodeMsi"`
 	DuplicatedCount      int64   `json:"-"`
+	BuildFailedCount     int64   `json:"buildFailedCount"`
+	VetFailedCount       int64   `json:"vetFailedCount"`
+	PanickedCount        int64   `json:"panickedCount"`
+	CachedCount          int64   `json:"cachedCount,omitempty"`
+	AcceptedCount        int64   `json:"acceptedCount,omitempty"`
+	// ChangedLinesCount and ChangedLinesMsi cover the mutations of changed lines, see --changed-since
+	ChangedLinesCount         int64   `json:"changedLinesCount,omitempty"`
+	ChangedLinesMsi           float64 `json:"changedLinesMsi,omitempty"`
+	ChangedLinesDetectedCount int64   `json:"-"`
 }
 
+// MutantStatus result of testing one mutation
+type MutantStatus string
+
+// Statuses of tested mutations
+const (
+	// StatusKilled the tests failed on the mutation
+	StatusKilled MutantStatus = "killed"
+	// StatusEscaped the tests passed on the mutation
+	StatusEscaped MutantStatus = "escaped"
+	// StatusPanicked a test panicked on the mutation
+	StatusPanicked MutantStatus = "panicked"
+	// StatusTimedOut the tests did not finish in time on the mutation
+	StatusTimedOut MutantStatus = "timedOut"
+	// StatusBuildFailed the mutated package or its tests do not compile
+	StatusBuildFailed MutantStatus = "buildFailed"
+	// StatusVetFailed the mutated package does not pass the vet checks of go test
+	StatusVetFailed MutantStatus = "vetFailed"
+	// StatusNotCovered no test executes the mutated code, so the mutation was not tested
+	StatusNotCovered MutantStatus = "notCovered"
+	// StatusAccepted the mutation survived, but it is listed in the accepted mutations file
+	StatusAccepted MutantStatus = "accepted"
+	// StatusSkipped the exec command skipped the mutation
+	StatusSkipped MutantStatus = "skipped"
+	// StatusError the mutation could not be tested
+	StatusError MutantStatus = "error"
+)
+
 // Mutant report by mutant for one mutation on one file
 type Mutant struct {
 	Mutator       Mutator `json:"mutator"`
 	Diff          string  `json:"diff"`
 	ProcessOutput string  `json:"processOutput,omitempty"`
+	Checksum      string  `json:"checksum,omitempty"`
+	// ID identifies the mutation across runs, unlike the checksum it only changes if the mutated code changes
+	ID string `json:"id,omitempty"`
+	// Package import path of the mutated package, or its path if the import path is unknown
+	Package string `json:"package,omitempty"`
+	// Function which contains the mutation, methods are prefixed with their receiver type
+	Function string `json:"function,omitempty"`
+
+	Status MutantStatus `json:"status,omitempty"`
+	// KilledBy tests which failed on the mutation
+	KilledBy []Test `json:"killedBy,omitempty"`
+	// Stage of tiered test execution which detected the mutation, or the last one if it survived all of them
+	Stage string `json:"stage,omitempty"`
+	// Consumer directory of the consumer module whose tests detected the mutation, see --consumer
+	Consumer string `json:"consumer,omitempty"`
+	// Cached is set if the result was reused from an earlier run
+	Cached bool `json:"cached,omitempty"`
+	// OnChangedLines is set if the mutation changes a line which changed since the ref of --changed-since
+	OnChangedLines bool `json:"onChangedLines,omitempty"`
+	// Acceptance why the surviving mutation is accepted, it is set for mutations with the status accepted
+	Acceptance *Acceptance `json:"acceptance,omitempty"`
+}
+
+// Acceptance entry of the accepted mutations file, which records a surviving mutation that does not count against the score
+type Acceptance struct {
+	ID      string `json:"id" yaml:"id"`
+	Mutator string `json:"mutator,omitempty" yaml:"mutator,omitempty"`
+	// File file and line of the mutation, e.g. example/example.go:17
+	File   string `json:"file,omitempty" yaml:"file,omitempty"`
+	Reason string `json:"reason" yaml:"reason"`
+	Owner  string `json:"owner,omitempty" yaml:"owner,omitempty"`
+	// Expires date (YYYY-MM-DD) from which on the mutation is not accepted anymore
+	Expires string `json:"expires,omitempty" yaml:"expires,omitempty"`
 }
 
 // Mutator mutator and changes in file
@@ -46,13 +214,64 @@ type Mutator struct {
 	OriginalStartLine  int64  `json:"originalStartLine"`
 }
 
+// Add adds a tested mutant to the report according to its status
+func (report *Report) Add(mutant Mutant) {
+	if mutant.Cached {
+		report.Stats.CachedCount++
+	}
+	if mutant.OnChangedLines {
+		switch mutant.Status {
+		case StatusKilled, StatusPanicked, StatusTimedOut, StatusSkipped, StatusError:
+			report.Stats.ChangedLinesCount++
+			report.Stats.ChangedLinesDetectedCount++
+		case StatusEscaped, StatusNotCovered:
+			report.Stats.ChangedLinesCount++
+		}
+	}
+
+	switch mutant.Status {
+	case StatusKilled:
+		report.Killed = append(report.Killed, mutant)
+		report.Stats.KilledCount++
+	case StatusEscaped:
+		report.Escaped = append(report.Escaped, mutant)
+		report.Stats.EscapedCount++
+	case StatusPanicked:
+		report.Panicked = append(report.Panicked, mutant)
+		report.Stats.PanickedCount++
+	case StatusTimedOut:
+		report.Timeouted = append(report.Timeouted, mutant)
+		report.Stats.TimeOutCount++
+	case StatusBuildFailed:
+		report.BuildFailed = append(report.BuildFailed, mutant)
+		report.Stats.BuildFailedCount++
+	case StatusVetFailed:
+		report.VetFailed = append(report.VetFailed, mutant)
+		report.Stats.VetFailedCount++
+	case StatusNotCovered:
+		report.NotCovered = append(report.NotCovered, mutant)
+		report.Stats.NotCoveredCount++
+	case StatusAccepted:
+		report.Accepted = append(report.Accepted, mutant)
+		report.Stats.AcceptedCount++
+	case StatusSkipped:
+		report.Stats.SkippedCount++
+	default:
+		report.Errored = append(report.Errored, mutant)
+		report.Stats.ErrorCount++
+	}
+}
+
 // Calculate calculation for final report
 func (report *Report) Calculate() {
 	report.Stats.Msi = report.MsiScore()
 	report.Stats.TotalMutantsCount = report.TotalCount()
+	report.Stats.MutationCodeCoverage = report.MutationCodeCoverage()
+	report.Stats.CoveredCodeMsi = report.CoveredCodeMsiScore()
+	report.Stats.ChangedLinesMsi = report.ChangedLinesMsiScore()
 }
 
-// MsiScore msi score calculation
+// MsiScore msi score calculation, mutations which do not build or vet are not counted
 func (report *Report) MsiScore() float64 {
 	total := report.TotalCount()
 
@@ -60,10 +279,46 @@ func (report *Report) MsiScore() float64 {
 		return 0.0
 	}
 
-	return float64(report.Stats.KilledCount+report.Stats.ErrorCount+report.Stats.SkippedCount) / float64(total)
+	return float64(report.DetectedCount()) / float64(total)
+}
+
+// MutationCodeCoverage share of the mutations whose code is executed by the tests
+func (report *Report) MutationCodeCoverage() float64 {
+	total := report.TotalCount()
+
+	if total == 0 {
+		return 0.0
+	}
+
+	return float64(total-report.Stats.NotCoveredCount) / float64(total)
+}
+
+// CoveredCodeMsiScore msi score of the mutations whose code is executed by the tests
+func (report *Report) CoveredCodeMsiScore() float64 {
+	covered := report.TotalCount() - report.Stats.NotCoveredCount
+
+	if covered == 0 {
+		return 0.0
+	}
+
+	return float64(report.DetectedCount()) / float64(covered)
+}
+
+// ChangedLinesMsiScore msi score of the mutations of changed lines
+func (report *Report) ChangedLinesMsiScore() float64 {
+	if report.Stats.ChangedLinesCount == 0 {
+		return 0.0
+	}
+
+	return float64(report.Stats.ChangedLinesDetectedCount) / float64(report.Stats.ChangedLinesCount)
+}
+
+// DetectedCount count of the mutations which were detected by the tests
+func (report *Report) DetectedCount() int64 {
+	return report.Stats.KilledCount + report.Stats.PanickedCount + report.Stats.TimeOutCount + report.Stats.ErrorCount + report.Stats.SkippedCount
 }
 
-// TotalCount total mutations count
+// TotalCount total mutations count without the ones which do not build or vet or are accepted
 func (report *Report) TotalCount() int64 {
-	return report.Stats.KilledCount + report.Stats.EscapedCount + report.Stats.ErrorCount + report.Stats.SkippedCount
+	return report.Stats.KilledCount + report.Stats.PanickedCount + report.Stats.EscapedCount + report.Stats.NotCoveredCount + report.Stats.TimeOutCount + report.Stats.ErrorCount + report.Stats.SkippedCount
 }
@@ -1,9 +1,12 @@
 package parser
 
 import (
+	"bufio"
+	"bytes"
 	"fmt"
 	"regexp"
 	"strconv"
+	"strings"
 )
 
 const (
@@ -13,6 +16,8 @@ const (
 
 var (
 	diffRegex *regexp.Regexp
+
+	gitHunkRegex = regexp.MustCompile(`^@@ -\d+(?:,\d+)? \+(\d+)(?:,(\d+))? @@`)
 )
 
 func init() {
@@ -54,3 +59,104 @@ func FindOriginalStartLine(diff []byte) int64 {
 
 	return changedLines[0]
 }
+
+// ChangedLines returns the lines of the original file which are changed by the unified diff (-u) output.
+// Lines which are only added are attributed to the original line in front of them.
+func ChangedLines(diff []byte) []int64 {
+	var lines []int64
+	seen := make(map[int64]struct{})
+
+	add := func(line int64) {
+		if _, ok := seen[line]; ok || line < 1 {
+			return
+		}
+
+		seen[line] = struct{}{}
+		lines = append(lines, line)
+	}
+
+	var originalLine int64
+	inHunk := false
+
+	scanner := bufio.NewScanner(bytes.NewReader(diff))
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		if match := diffRegex.FindStringSubmatch(line); match != nil {
+			start, err := strconv.ParseInt(match[1], 10, 64)
+			if err != nil {
+				inHunk = false
+
+				continue
+			}
+
+			originalLine = start
+			inHunk = true
+
+			continue
+		}
+		if !inHunk || len(line) == 0 {
+			continue
+		}
+
+		switch line[0] {
+		case '-':
+			add(originalLine)
+			originalLine++
+		case '+':
+			add(originalLine - 1)
+		case ' ':
+			originalLine++
+		}
+	}
+
+	return lines
+}
+
+// ParseGitDiff returns the added or modified lines of every file of the output of git diff --unified=0.
+// Files are keyed by their path relative to the root of the repository, deleted files are left out.
+func ParseGitDiff(diff []byte) map[string][]int64 {
+	files := make(map[string][]int64)
+	var file string
+
+	scanner := bufio.NewScanner(bytes.NewReader(diff))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		if strings.HasPrefix(line, "+++ ") {
+			file = strings.TrimPrefix(line, "+++ ")
+			if file == "/dev/null" {
+				file = ""
+			} else {
+				file = strings.TrimPrefix(file, "b/")
+			}
+
+			continue
+		}
+
+		match := gitHunkRegex.FindStringSubmatch(line)
+		if match == nil || file == "" {
+			continue
+		}
+
+		start, err := strconv.ParseInt(match[1], 10, 64)
+		if err != nil {
+			continue
+		}
+		count := int64(1)
+		if match[2] != "" {
+			count, err = strconv.ParseInt(match[2], 10, 64)
+			if err != nil {
+				continue
+			}
+		}
+
+		// A count of zero only removes lines, which leaves nothing to mutate
+		for l := start; l < start+count; l++ {
+			files[file] = append(files[file], l)
+		}
+	}
+
+	return files
+}
@@ -1,6 +1,7 @@
 package parser
 
 import (
+	"reflect"
 	"testing"
 )
 
@@ -85,3 +86,81 @@ func TestParseDiffOutput(t *testing.T) {
 		})
 	}
 }
+
+func TestChangedLines(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected []int64
+	}{
+		{
+			name: "changed line",
+			input: "--- Original\n+++ New\n@@ -20,7 +20,7 @@\n }\n \n func doo() {\n-\tddd := 6\n+\tddd := 5\n \tslog.Info(strconv.Itoa(ddd))\n" +
+				" \tfmt.Println(\"doo\")\n }\n",
+			expected: []int64{23},
+		},
+		{
+			name: "removed and added lines in two hunks",
+			input: "--- Original\n+++ New\n@@ -1,4 +1,3 @@\n package a\n-\n-func a() {}\n+func b() {}\n" +
+				"@@ -10,3 +9,4 @@\n x\n+y\n z\n",
+			expected: []int64{2, 3, 10},
+		},
+		{
+			name:     "empty input",
+			input:    "",
+			expected: nil,
+		},
+		{
+			name:     "invalid line numbers",
+			input:    "@@ -abc +def @@\n-garbage\n+garbage",
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ChangedLines([]byte(tt.input))
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("ChangedLines() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParseGitDiff(t *testing.T) {
+	diff := "diff --git a/a.go b/a.go\n" +
+		"index 1111111..2222222 100644\n" +
+		"--- a/a.go\n" +
+		"+++ b/a.go\n" +
+		"@@ -3 +3 @@ func a() {\n" +
+		"-\treturn 1\n" +
+		"+\treturn 2\n" +
+		"@@ -10,0 +11,2 @@ func b() {\n" +
+		"+\tx++\n" +
+		"+\ty++\n" +
+		"@@ -20,2 +22,0 @@\n" +
+		"-\tz++\n" +
+		"-\tz++\n" +
+		"diff --git a/sub/new.go b/sub/new.go\n" +
+		"new file mode 100644\n" +
+		"--- /dev/null\n" +
+		"+++ b/sub/new.go\n" +
+		"@@ -0,0 +1,2 @@\n" +
+		"+package sub\n" +
+		"+\n" +
+		"diff --git a/old.go b/old.go\n" +
+		"deleted file mode 100644\n" +
+		"--- a/old.go\n" +
+		"+++ /dev/null\n" +
+		"@@ -1 +0,0 @@\n" +
+		"-package old\n"
+
+	expected := map[string][]int64{
+		"a.go":       {3, 11, 12},
+		"sub/new.go": {1, 2},
+	}
+
+	if got := ParseGitDiff([]byte(diff)); !reflect.DeepEqual(got, expected) {
+		t.Errorf("ParseGitDiff() = %v, want %v", got, expected)
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"html/template"
 	"math"
 	"os"
+	"sort"
 	"strings"
 
 	"github.com/avito-tech/go-mutesting/internal/models"
@@ -43,9 +44,13 @@ func MakeHTMLReport(report models.Report) error {
 	data := struct {
 		Stats          models.Stats
 		GroupedMutants map[string][]models.Mutant
+		TestKills      []testKills
+		KilledMutants  []models.Mutant
 	}{
 		Stats:          report.Stats,
 		GroupedMutants: groupedMutants,
+		TestKills:      countTestKills(report),
+		KilledMutants:  killedMutants(report),
 	}
 
 	err = t.Execute(file, data)
@@ -114,3 +119,60 @@ func groupEscapedMutants(escaped []models.Mutant) map[string][]models.Mutant {
 
 	return groupedMutants
 }
+
+// killedMutants returns the killed and panicked mutants ordered by their file and line.
+func killedMutants(report models.Report) []models.Mutant {
+	killed := append(append([]models.Mutant(nil), report.Killed...), report.Panicked...)
+
+	sort.SliceStable(killed, func(i, j int) bool {
+		if killed[i].Mutator.OriginalFilePath != killed[j].Mutator.OriginalFilePath {
+			return killed[i].Mutator.OriginalFilePath < killed[j].Mutator.OriginalFilePath
+		}
+
+		return killed[i].Mutator.OriginalStartLine < killed[j].Mutator.OriginalStartLine
+	})
+
+	return killed
+}
+
+// testKills number of mutants killed by one test
+type testKills struct {
+	Test   models.Test
+	Killed int
+}
+
+// countTestKills counts the killed mutants of every test, tests which ran on the original code but killed nothing are included.
+func countTestKills(report models.Report) []testKills {
+	kills := make(map[models.Test]int)
+
+	for _, baseline := range report.Baselines {
+		for _, test := range baseline.Tests {
+			kills[test] += 0
+		}
+	}
+	for _, mutants := range [][]models.Mutant{report.Killed, report.Panicked} {
+		for _, mutant := range mutants {
+			for _, test := range mutant.KilledBy {
+				kills[test]++
+			}
+		}
+	}
+
+	counts := make([]testKills, 0, len(kills))
+	for test, killed := range kills {
+		counts = append(counts, testKills{Test: test, Killed: killed})
+	}
+
+	sort.Slice(counts, func(i, j int) bool {
+		if counts[i].Killed != counts[j].Killed {
+			return counts[i].Killed > counts[j].Killed
+		}
+		if counts[i].Test.Package != counts[j].Test.Package {
+			return counts[i].Test.Package < counts[j].Test.Package
+		}
+
+		return counts[i].Test.Name < counts[j].Test.Name
+	})
+
+	return counts
+}
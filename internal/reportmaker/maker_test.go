@@ -3,6 +3,7 @@ package reportmaker
 import (
 	"encoding/json"
 	"os"
+	"strings"
 	"testing"
 
 	"github.com/avito-tech/go-mutesting/internal/models"
@@ -177,3 +178,61 @@ func TestFuncMap(t *testing.T) {
 	result = funcMap["hasPrefix"].(func(string, string) bool)("test_string", "string")
 	assert.False(t, result)
 }
+
+func TestCountTestKills(t *testing.T) {
+	foo := models.Test{Package: "example", Name: "TestFoo"}
+	bar := models.Test{Package: "example", Name: "TestBar"}
+	baz := models.Test{Package: "example", Name: "TestBaz"}
+
+	report := models.Report{
+		Killed: []models.Mutant{
+			{KilledBy: []models.Test{foo}},
+			{KilledBy: []models.Test{foo, bar}},
+		},
+		Panicked: []models.Mutant{
+			{KilledBy: []models.Test{bar}},
+			{KilledBy: []models.Test{foo}},
+		},
+		Baselines: []models.Baseline{
+			{Package: "example", Passed: true, Tests: []models.Test{bar, baz, foo}},
+		},
+	}
+
+	assert.Equal(t, []testKills{
+		{Test: foo, Killed: 3},
+		{Test: bar, Killed: 2},
+		{Test: baz, Killed: 0},
+	}, countTestKills(report))
+}
+
+func TestMakeHTMLReportTestKills(t *testing.T) {
+	report := models.Report{
+		Killed: []models.Mutant{
+			{
+				Mutator:  models.Mutator{MutatorName: "branch/if", OriginalFilePath: "example/b.go", OriginalStartLine: 7},
+				KilledBy: []models.Test{{Package: "example", Name: "TestFoo"}, {Package: "example", Name: "TestBar"}},
+			},
+			{
+				Mutator:  models.Mutator{MutatorName: "branch/else", OriginalFilePath: "example/a.go", OriginalStartLine: 3},
+				KilledBy: []models.Test{{Package: "example", Name: "TestFoo"}},
+			},
+		},
+	}
+
+	require.NoError(t, MakeHTMLReport(report))
+	defer func() {
+		_ = os.Remove(models.ReportHTMLFileName)
+	}()
+
+	content, err := os.ReadFile(models.ReportHTMLFileName)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "<td>TestFoo</td>")
+
+	// Every killed mutant is listed with the tests which killed it, ordered by file and line
+	assert.Contains(t, string(content), "Killed mutants (2)")
+	first := strings.Index(string(content), "<td>example/a.go:3</td>")
+	second := strings.Index(string(content), "<td>example/b.go:7</td>")
+	assert.Greater(t, first, 0)
+	assert.Greater(t, second, first)
+	assert.Contains(t, string(content), "<td>example.TestFoo, example.TestBar</td>")
+}
@@ -0,0 +1,209 @@
+package reportmaker
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/avito-tech/go-mutesting/internal/killmatrix"
+	"github.com/avito-tech/go-mutesting/internal/models"
+	"github.com/avito-tech/go-mutesting/internal/sampling"
+)
+
+// ReadJSONReport reads a report which was written by MakeJSONReport
+func ReadJSONReport(path string) (models.Report, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return models.Report{}, err
+	}
+
+	var report models.Report
+	if err := json.Unmarshal(content, &report); err != nil {
+		return models.Report{}, err
+	}
+
+	return report, nil
+}
+
+// Merge combines the given reports, e.g. of all shards of a run, into one report with recalculated stats.
+// Reports which share a mutant are refused, skipped mutants are only counted, so they could not be counted once.
+// The baseline of a package is only counted once. Sampling and rerun outcomes are combined if all reports were run with the same settings.
+func Merge(reports []models.Report) (models.Report, error) {
+	merged := models.Report{}
+
+	// seen index of the report of every mutant by its key
+	seen := make(map[string]int)
+	baselines := make(map[string]struct{})
+	withKillMatrix := false
+
+	for i, report := range reports {
+		categories := []struct {
+			status  models.MutantStatus
+			mutants []models.Mutant
+		}{
+			{models.StatusKilled, report.Killed},
+			{models.StatusEscaped, report.Escaped},
+			{models.StatusPanicked, report.Panicked},
+			{models.StatusTimedOut, report.Timeouted},
+			{models.StatusBuildFailed, report.BuildFailed},
+			{models.StatusVetFailed, report.VetFailed},
+			{models.StatusNotCovered, report.NotCovered},
+			{models.StatusAccepted, report.Accepted},
+			{models.StatusError, report.Errored},
+		}
+
+		for _, category := range categories {
+			for _, mutant := range category.mutants {
+				// Reports of older versions only identify mutants by their checksum
+				key := mutant.ID
+				if key == "" {
+					key = mutant.Checksum
+				}
+				if key != "" {
+					if j, ok := seen[key]; ok && j != i {
+						return models.Report{}, fmt.Errorf("mutant %s is part of report %d and %d, only reports which do not overlap can be merged", key, j+1, i+1)
+					}
+					seen[key] = i
+				}
+
+				// They do not record the status either
+				if mutant.Status == "" {
+					mutant.Status = category.status
+				}
+
+				merged.Add(mutant)
+			}
+		}
+
+		// Skipped mutants are only counted
+		merged.Stats.SkippedCount += report.Stats.SkippedCount
+
+		for _, baseline := range report.Baselines {
+			if _, ok := baselines[baseline.Package]; ok {
+				continue
+			}
+			baselines[baseline.Package] = struct{}{}
+
+			merged.Baselines = append(merged.Baselines, baseline)
+		}
+
+		if report.KillMatrix != nil {
+			withKillMatrix = true
+		}
+	}
+
+	sort.Slice(merged.Baselines, func(i, j int) bool {
+		return merged.Baselines[i].Package < merged.Baselines[j].Package
+	})
+
+	if withKillMatrix {
+		merged.KillMatrix = killmatrix.Build(merged)
+	}
+
+	var err error
+	merged.Sampling, err = mergeSampling(reports)
+	if err != nil {
+		return models.Report{}, err
+	}
+	merged.Rerun, err = mergeRerun(reports)
+	if err != nil {
+		return models.Report{}, err
+	}
+
+	merged.Calculate()
+
+	return merged, nil
+}
+
+// mergeSampling adds up the sampling of the given reports, which have to be sampled with the same settings.
+// The estimated mutation score is calculated again from the combined counts.
+func mergeSampling(reports []models.Report) (*models.Sampling, error) {
+	if len(reports) == 0 || reports[0].Sampling == nil {
+		for i, report := range reports {
+			if report.Sampling != nil {
+				return nil, fmt.Errorf("report %d is sampled but report 1 is not, only reports with the same sampling can be merged", i+1)
+			}
+		}
+
+		return nil, nil
+	}
+
+	first := *reports[0].Sampling
+	merged := &models.Sampling{
+		Sample:     first.Sample,
+		Seed:       first.Seed,
+		TimeBudget: first.TimeBudget,
+	}
+
+	for i, report := range reports {
+		s := report.Sampling
+		if s == nil || s.Sample != first.Sample || s.Seed != first.Seed || s.TimeBudget != first.TimeBudget {
+			return nil, fmt.Errorf("report %d is not sampled like report 1, only reports with the same --sample, --seed and --time-budget can be merged", i+1)
+		}
+
+		merged.Population += s.Population
+		merged.Sampled += s.Sampled
+		merged.OverBudget += s.OverBudget
+		merged.Prioritized += s.Prioritized
+		merged.Detected += s.Detected
+		merged.Counted += s.Counted
+	}
+
+	sampling.Estimate(merged)
+
+	return merged, nil
+}
+
+// mergeRerun combines the rerun outcomes of the given reports, which have to rerun the same earlier report.
+func mergeRerun(reports []models.Report) (*models.RerunDelta, error) {
+	if len(reports) == 0 || reports[0].Rerun == nil {
+		for i, report := range reports {
+			if report.Rerun != nil {
+				return nil, fmt.Errorf("report %d reruns escaped mutants but report 1 does not, only reports which rerun the same report can be merged", i+1)
+			}
+		}
+
+		return nil, nil
+	}
+
+	first := reports[0].Rerun
+	merged := &models.RerunDelta{
+		Report:  first.Report,
+		Total:   first.Total,
+		Killed:  []string{},
+		Escaped: []string{},
+		Stale:   []models.Mutant{},
+	}
+
+	seen := make(map[string]struct{})
+	stale := make(map[string]struct{})
+	for i, report := range reports {
+		r := report.Rerun
+		if r == nil || r.Report != first.Report || r.Total != first.Total {
+			return nil, fmt.Errorf("report %d does not rerun the same escaped mutants as report 1, only reports which rerun the same report can be merged", i+1)
+		}
+
+		for _, id := range r.Killed {
+			if _, ok := seen[id]; !ok {
+				seen[id] = struct{}{}
+				merged.Killed = append(merged.Killed, id)
+			}
+		}
+		for _, id := range r.Escaped {
+			if _, ok := seen[id]; !ok {
+				seen[id] = struct{}{}
+				merged.Escaped = append(merged.Escaped, id)
+			}
+		}
+		// Every shard finds the same stale mutants
+		for _, mutant := range r.Stale {
+			if _, ok := stale[mutant.ID]; !ok {
+				stale[mutant.ID] = struct{}{}
+				merged.Stale = append(merged.Stale, mutant)
+			}
+		}
+	}
+
+	return merged, nil
+}
@@ -0,0 +1,108 @@
+package reportmaker
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/avito-tech/go-mutesting/internal/models"
+)
+
+func TestMerge(t *testing.T) {
+	first := models.Report{
+		Stats:  models.Stats{SkippedCount: 1},
+		Killed: []models.Mutant{{Checksum: "a", Status: models.StatusKilled}},
+		// Reports of older versions do not record the status
+		Escaped:   []models.Mutant{{Checksum: "b"}},
+		Baselines: []models.Baseline{{Package: "example/sub", Passed: true}},
+	}
+	second := models.Report{
+		Stats:      models.Stats{SkippedCount: 2},
+		Killed:     []models.Mutant{{Checksum: "c", Status: models.StatusKilled}},
+		NotCovered: []models.Mutant{{Checksum: "d", Status: models.StatusNotCovered}},
+		Baselines:  []models.Baseline{{Package: "example", Passed: true}, {Package: "example/sub", Passed: true}},
+	}
+
+	merged, err := Merge([]models.Report{first, second})
+	require.NoError(t, err)
+
+	assert.Equal(t, models.Stats{
+		TotalMutantsCount:    7,
+		KilledCount:          2,
+		NotCoveredCount:      1,
+		EscapedCount:         1,
+		SkippedCount:         3,
+		Msi:                  5.0 / 7.0,
+		MutationCodeCoverage: 6.0 / 7.0,
+		CoveredCodeMsi:       5.0 / 6.0,
+	}, merged.Stats)
+	assert.Equal(t, []models.Mutant{{Checksum: "b", Status: models.StatusEscaped}}, merged.Escaped)
+	assert.Equal(t, []models.Baseline{{Package: "example", Passed: true}, {Package: "example/sub", Passed: true}}, merged.Baselines)
+	assert.Nil(t, merged.KillMatrix)
+	assert.Nil(t, merged.Sampling)
+	assert.Nil(t, merged.Rerun)
+
+	// Skipped mutants are only counted, so reports which overlap would count them twice
+	_, err = Merge([]models.Report{first, first})
+	assert.ErrorContains(t, err, "mutant a is part of report 1 and 2")
+}
+
+func TestMergeSampling(t *testing.T) {
+	first := models.Report{
+		Killed:   []models.Mutant{{ID: "a", Status: models.StatusKilled}},
+		Sampling: &models.Sampling{Sample: "0.5", Seed: 1, Population: 4, Sampled: 2, OverBudget: 1, Detected: 1, Counted: 1},
+	}
+	second := models.Report{
+		Escaped:  []models.Mutant{{ID: "b", Status: models.StatusEscaped}},
+		Sampling: &models.Sampling{Sample: "0.5", Seed: 1, Population: 6, Sampled: 3, OverBudget: 2, Detected: 0, Counted: 1},
+	}
+
+	merged, err := Merge([]models.Report{first, second})
+	require.NoError(t, err)
+	assert.Equal(t, "0.5", merged.Sampling.Sample)
+	assert.Equal(t, 10, merged.Sampling.Population)
+	assert.Equal(t, 5, merged.Sampling.Sampled)
+	assert.Equal(t, 3, merged.Sampling.OverBudget)
+	assert.Equal(t, int64(1), merged.Sampling.Detected)
+	assert.Equal(t, int64(2), merged.Sampling.Counted)
+	assert.Greater(t, merged.Sampling.MsiLow, 0.0)
+	assert.Less(t, merged.Sampling.MsiHigh, 1.0)
+
+	second.Sampling.Seed = 2
+	_, err = Merge([]models.Report{first, second})
+	assert.ErrorContains(t, err, "report 2 is not sampled like report 1")
+
+	second.Sampling = nil
+	_, err = Merge([]models.Report{first, second})
+	assert.ErrorContains(t, err, "report 2 is not sampled like report 1")
+
+	_, err = Merge([]models.Report{second, first})
+	assert.ErrorContains(t, err, "report 2 is sampled but report 1 is not")
+}
+
+func TestMergeRerun(t *testing.T) {
+	stale := models.Mutant{ID: "s", Status: models.StatusEscaped}
+	first := models.Report{
+		Killed: []models.Mutant{{ID: "a", Status: models.StatusKilled}},
+		Rerun:  &models.RerunDelta{Report: "old.json", Total: 3, Killed: []string{"a"}, Escaped: []string{}, Stale: []models.Mutant{stale}},
+	}
+	second := models.Report{
+		Escaped: []models.Mutant{{ID: "b", Status: models.StatusEscaped}},
+		Rerun:   &models.RerunDelta{Report: "old.json", Total: 3, Killed: []string{}, Escaped: []string{"b"}, Stale: []models.Mutant{stale}},
+	}
+
+	merged, err := Merge([]models.Report{first, second})
+	require.NoError(t, err)
+	assert.Equal(t, &models.RerunDelta{
+		Report:  "old.json",
+		Total:   3,
+		Killed:  []string{"a"},
+		Escaped: []string{"b"},
+		Stale:   []models.Mutant{stale},
+	}, merged.Rerun)
+
+	second.Rerun.Report = "other.json"
+	_, err = Merge([]models.Report{first, second})
+	assert.ErrorContains(t, err, "report 2 does not rerun the same escaped mutants as report 1")
+}
@@ -0,0 +1,246 @@
+package runner
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/avito-tech/go-mutesting/internal/models"
+)
+
+// TestEvent is one event of the output of go test -json, see "go doc test2json".
+type TestEvent struct {
+	Action      string
+	Package     string
+	ImportPath  string
+	Test        string
+	Output      string
+	FailedBuild string
+}
+
+// TestResult summarizes the events of one go test -json run.
+type TestResult struct {
+	// Status is the status of the mutation which was tested.
+	Status models.MutantStatus
+	// Output holds the plain text output of go test.
+	Output string
+	// FailedTests lists the tests which failed in the order they finished.
+	FailedTests []models.Test
+	// Tests lists all tests which finished in the order they finished.
+	Tests []models.Test
+}
+
+// vetHeaderRegex matches the header go test prints in front of the diagnostics of vet, e.g. "# [github.com/foo/bar]".
+var vetHeaderRegex = regexp.MustCompile(`^# \[.+\]$`)
+
+// ParseTestEvents classifies the outcome of a go test -json run whose output is read from r.
+// Lines which are not JSON events, e.g. output of the go tool before the tests started, are kept as plain output.
+func ParseTestEvents(r io.Reader) TestResult {
+	var output strings.Builder
+	var failedTests, tests []models.Test
+
+	var buildFailed, vetFailed, testFailed, panicked, timedOut, started bool
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+
+	for scanner.Scan() {
+		line := scanner.Bytes()
+
+		var e TestEvent
+		if len(line) == 0 || line[0] != '{' || json.Unmarshal(line, &e) != nil {
+			output.Write(line)
+			output.WriteByte('\n')
+
+			continue
+		}
+
+		output.WriteString(e.Output)
+
+		switch e.Action {
+		case "build-output":
+			if vetHeaderRegex.MatchString(strings.TrimSpace(e.Output)) {
+				vetFailed = true
+			}
+		case "build-fail":
+			buildFailed = true
+		case "start", "run":
+			started = true
+		case "output":
+			if strings.HasPrefix(e.Output, "panic: test timed out after") {
+				timedOut = true
+			} else if strings.HasPrefix(e.Output, "panic: ") {
+				panicked = true
+			}
+		case "pass", "skip":
+			if e.Test != "" {
+				tests = append(tests, models.Test{Package: e.Package, Name: e.Test})
+			}
+		case "fail":
+			if e.FailedBuild != "" {
+				buildFailed = true
+			} else {
+				testFailed = true
+			}
+
+			if e.Test != "" {
+				test := models.Test{Package: e.Package, Name: e.Test}
+				tests = append(tests, test)
+				failedTests = append(failedTests, test)
+			}
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		output.WriteString(err.Error())
+	}
+
+	result := TestResult{
+		Output:      output.String(),
+		FailedTests: failedTests,
+		Tests:       tests,
+	}
+
+	switch {
+	case buildFailed && vetFailed:
+		result.Status = models.StatusVetFailed
+	case buildFailed:
+		result.Status = models.StatusBuildFailed
+	case timedOut:
+		result.Status = models.StatusTimedOut
+	case panicked:
+		result.Status = models.StatusPanicked
+	case testFailed:
+		result.Status = models.StatusKilled
+	case started:
+		result.Status = models.StatusEscaped
+	default:
+		// go test did not even get to run the tests
+		result.Status = models.StatusError
+	}
+
+	return result
+}
+
+// StatusOfExitCode returns the status of a mutation which was tested by an exec command with the given exit code.
+func StatusOfExitCode(exitCode int) models.MutantStatus {
+	switch exitCode {
+	case 0:
+		return models.StatusKilled
+	case 1:
+		return models.StatusEscaped
+	case 2:
+		return models.StatusSkipped
+	case TimeoutExitCode:
+		return models.StatusTimedOut
+	default:
+		return models.StatusError
+	}
+}
+
+// killGrace is the time the go tool gets on top of the timeout of the tests before it is killed.
+// The timeout of the test binary only starts once the tests are built, and the binary reports its own timeout together with the hanging test.
+const killGrace = 30 * time.Second
+
+// GoTest describes a run of the tests of packages with go test -json, or of a compiled test binary through go tool test2json.
+type GoTest struct {
+	// Packages whose tests are run by go test
+	Packages []string
+	// Binary compiled test binary which is run instead of go test, Package is the import path its events are reported for
+	Binary  string
+	Package string
+	// Dir working directory of the run, the current directory if it is empty
+	Dir string
+	// Env additional environment variables of the run
+	Env []string
+	// Overlay file which replaces source files, see "go help build"
+	Overlay string
+	// ModFile replaces the go.mod of the module, see "go help build"
+	ModFile string
+	// Flags additional arguments of go test, e.g. build tags
+	Flags []string
+	// RunPattern selects the tests which are run, all tests are run if it is empty
+	RunPattern string
+	// FailFast stops the tests at the first failing test
+	FailFast bool
+	// CoverPackage and CoverProfile record the coverage of the tests of go test
+	CoverPackage string
+	CoverProfile string
+	// Timeout after which the test binary stops the tests, the go tool is killed killGrace later. Zero means no timeout
+	Timeout time.Duration
+}
+
+// Command returns the command which runs the tests.
+func (t GoTest) Command() *exec.Cmd {
+	// The events of go test -json tell build and vet failures, panics and timeouts apart from failing tests
+	var args []string
+	flag := "-"
+	if t.Binary != "" {
+		args = []string{"tool", "test2json", "-t", "-p", t.Package, t.Binary, "-test.v=test2json"}
+		flag = "-test."
+	} else {
+		args = []string{"test", "-json"}
+		if t.ModFile != "" {
+			args = append(args, "-mod=mod", "-modfile", t.ModFile)
+		}
+		if t.Overlay != "" {
+			args = append(args, "-overlay", t.Overlay)
+		}
+	}
+
+	if t.FailFast {
+		args = append(args, flag+"failfast")
+	}
+	args = append(args, t.Flags...)
+	if t.CoverProfile != "" {
+		args = append(args, "-coverpkg", t.CoverPackage, "-coverprofile", t.CoverProfile)
+	}
+	if t.Timeout > 0 {
+		// The test binary reports its own timeout, which is more helpful than getting killed
+		args = append(args, flag+"timeout", t.Timeout.String())
+	}
+	if t.RunPattern != "" {
+		args = append(args, flag+"run", t.RunPattern)
+	}
+	args = append(args, t.Packages...)
+
+	cmd := exec.Command("go", args...)
+	cmd.Dir = t.Dir
+	cmd.Env = append(os.Environ(), t.Env...)
+
+	return cmd
+}
+
+// Run runs the tests and classifies their outcome by their events, see ParseTestEvents.
+// It returns the exit code of the go tool as well, which is TimeoutExitCode if the tests had to be killed.
+func (t GoTest) Run() (TestResult, int, error) {
+	cmd := t.Command()
+
+	var output bytes.Buffer
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+
+	killTimeout := t.Timeout
+	if killTimeout > 0 {
+		killTimeout += killGrace
+	}
+
+	exitCode, err := Execute(cmd, killTimeout)
+	if err != nil {
+		return TestResult{}, exitCode, err
+	}
+
+	result := ParseTestEvents(&output)
+	if exitCode == TimeoutExitCode {
+		// go test itself got killed, so its events are incomplete
+		result.Status = models.StatusTimedOut
+	}
+
+	return result, exitCode, nil
+}
@@ -0,0 +1,103 @@
+package runner
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/avito-tech/go-mutesting/internal/models"
+)
+
+func TestParseTestEvents(t *testing.T) {
+	for name, expected := range map[string]models.MutantStatus{
+		"escaped":     models.StatusEscaped,
+		"killed":      models.StatusKilled,
+		"buildfailed": models.StatusBuildFailed,
+		"vetfailed":   models.StatusVetFailed,
+		"panicked":    models.StatusPanicked,
+		"timedout":    models.StatusTimedOut,
+	} {
+		t.Run(name, func(t *testing.T) {
+			f, err := os.Open(filepath.Join("testdata", "gotest", name+".json"))
+			require.NoError(t, err)
+			defer func() { _ = f.Close() }()
+
+			result := ParseTestEvents(f)
+			assert.Equal(t, expected, result.Status)
+			assert.NotContains(t, result.Output, `"Action"`)
+		})
+	}
+}
+
+func TestParseTestEventsTests(t *testing.T) {
+	f, err := os.Open(filepath.Join("testdata", "gotest", "killed.json"))
+	require.NoError(t, err)
+	defer func() { _ = f.Close() }()
+
+	result := ParseTestEvents(f)
+	assert.Equal(t, []models.Test{{Package: "jt", Name: "TestAdd"}}, result.FailedTests)
+	assert.Equal(t, []models.Test{{Package: "jt", Name: "TestAdd"}, {Package: "jt", Name: "TestOther"}}, result.Tests)
+}
+
+func TestParseTestEventsWithoutEvents(t *testing.T) {
+	result := ParseTestEvents(strings.NewReader("go: unknown flag -foo\n"))
+	assert.Equal(t, models.StatusError, result.Status)
+	assert.Equal(t, "go: unknown flag -foo\n", result.Output)
+}
+
+func TestStatusOfExitCode(t *testing.T) {
+	assert.Equal(t, models.StatusKilled, StatusOfExitCode(0))
+	assert.Equal(t, models.StatusEscaped, StatusOfExitCode(1))
+	assert.Equal(t, models.StatusSkipped, StatusOfExitCode(2))
+	assert.Equal(t, models.StatusTimedOut, StatusOfExitCode(TimeoutExitCode))
+	assert.Equal(t, models.StatusError, StatusOfExitCode(3))
+}
+
+func TestGoTestCommand(t *testing.T) {
+	cmd := GoTest{
+		Packages:   []string{"example.com/foo"},
+		Overlay:    "overlay.json",
+		ModFile:    "consumer.mod",
+		Flags:      []string{"-tags", "integration"},
+		RunPattern: "^TestFoo$",
+		FailFast:   true,
+		Timeout:    time.Second,
+	}.Command()
+	assert.Equal(t, []string{
+		"go", "test", "-json", "-mod=mod", "-modfile", "consumer.mod", "-overlay", "overlay.json",
+		"-failfast", "-tags", "integration", "-timeout", "1s", "-run", "^TestFoo$", "example.com/foo",
+	}, cmd.Args)
+
+	cmd = GoTest{
+		Binary:     "foo.test",
+		Package:    "example.com/foo",
+		Dir:        "foo",
+		Env:        []string{"FOO=1"},
+		RunPattern: "^TestFoo$",
+		Timeout:    time.Second,
+	}.Command()
+	assert.Equal(t, []string{
+		"go", "tool", "test2json", "-t", "-p", "example.com/foo", "foo.test", "-test.v=test2json",
+		"-test.timeout", "1s", "-test.run", "^TestFoo$",
+	}, cmd.Args)
+	assert.Equal(t, "foo", cmd.Dir)
+	assert.Contains(t, cmd.Env, "FOO=1")
+}
+
+func TestGoTestRunTimeout(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module hang\n\ngo 1.21\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "hang_test.go"), []byte("package hang\n\nimport (\n\t\"testing\"\n\t\"time\"\n)\n\nfunc TestHang(t *testing.T) {\n\ttime.Sleep(time.Minute)\n}\n"), 0o644))
+
+	// The test binary reports its own timeout before the go tool would be killed
+	result, exitCode, err := GoTest{Packages: []string{"."}, Dir: dir, Timeout: time.Second}.Run()
+	require.NoError(t, err)
+	assert.Equal(t, models.StatusTimedOut, result.Status)
+	assert.NotEqual(t, TimeoutExitCode, exitCode)
+	assert.Contains(t, result.Output, "panic: test timed out after 1s")
+}
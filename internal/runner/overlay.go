@@ -0,0 +1,49 @@
+package runner
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Overlay is the JSON structure which is understood by the -overlay flag of the go tool.
+type Overlay struct {
+	// Replace maps the path of a source file to the path of the file which the go tool should use instead.
+	Replace map[string]string
+}
+
+// WriteOverlay writes an overlay file to path which makes the go tool read replacement instead of original.
+func WriteOverlay(path string, original string, replacement string) error {
+	return WriteOverlayFiles(path, map[string]string{
+		original: replacement,
+	})
+}
+
+// WriteOverlayFiles writes an overlay file to path which makes the go tool read the replacement of every original.
+// All paths are made absolute since the go tool resolves relative paths against its own working directory.
+func WriteOverlayFiles(path string, replace map[string]string) error {
+	overlay := Overlay{
+		Replace: make(map[string]string, len(replace)),
+	}
+
+	for original, replacement := range replace {
+		originalAbs, err := filepath.Abs(original)
+		if err != nil {
+			return fmt.Errorf("could not absolute the file path of %q: %w", original, err)
+		}
+		replacementAbs, err := filepath.Abs(replacement)
+		if err != nil {
+			return fmt.Errorf("could not absolute the file path of %q: %w", replacement, err)
+		}
+
+		overlay.Replace[originalAbs] = replacementAbs
+	}
+
+	content, err := json.Marshal(overlay)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, content, 0666)
+}
@@ -0,0 +1,57 @@
+package runner
+
+import (
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteOverlay(t *testing.T) {
+	dir := t.TempDir()
+	overlayFile := filepath.Join(dir, "overlay.json")
+
+	require.NoError(t, WriteOverlay(overlayFile, "original.go", filepath.Join(dir, "mutated.go")))
+
+	content, err := os.ReadFile(overlayFile)
+	require.NoError(t, err)
+
+	var overlay Overlay
+	require.NoError(t, json.Unmarshal(content, &overlay))
+
+	originalAbs, err := filepath.Abs("original.go")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{originalAbs: filepath.Join(dir, "mutated.go")}, overlay.Replace)
+}
+
+func TestWriteOverlayIsUsedByGoTool(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go tool is not available")
+	}
+
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "go.mod"), "module overlaytest\n")
+	writeFile(t, filepath.Join(dir, "main.go"), "package main\n\nfunc main() { println(\"original\") }\n")
+	writeFile(t, filepath.Join(dir, "main.go.0"), "package main\n\nfunc main() { println(\"mutated\") }\n")
+
+	overlayFile := filepath.Join(dir, "overlay.json")
+	require.NoError(t, WriteOverlay(overlayFile, filepath.Join(dir, "main.go"), filepath.Join(dir, "main.go.0")))
+
+	cmd := exec.Command("go", "run", "-overlay", overlayFile, ".")
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	require.NoError(t, err, string(out))
+	assert.Equal(t, "mutated\n", string(out))
+
+	original, err := os.ReadFile(filepath.Join(dir, "main.go"))
+	require.NoError(t, err)
+	assert.Contains(t, string(original), "original")
+}
+
+func writeFile(t *testing.T, path string, content string) {
+	require.NoError(t, os.WriteFile(path, []byte(content), 0666))
+}
@@ -0,0 +1,93 @@
+package runner
+
+import (
+	"runtime"
+	"sync"
+)
+
+// Workers returns the number of workers to use for the given jobs option.
+// A value lower than one means that one worker per available CPU is used.
+func Workers(jobs int) int {
+	if jobs < 1 {
+		return runtime.NumCPU()
+	}
+
+	return jobs
+}
+
+// Run calls task for every index in [0, n) on the given number of workers.
+// The results are handed to emit strictly in index order and from a single goroutine,
+// so emit can write to the console and update shared state without further synchronization.
+func Run[T any](workers int, n int, task func(i int) T, emit func(i int, result T)) {
+	if workers < 1 {
+		workers = 1
+	}
+	if workers > n {
+		workers = n
+	}
+
+	indexes := make(chan int)
+	results := make([]T, n)
+	done := make([]chan struct{}, n)
+	for i := range done {
+		done[i] = make(chan struct{})
+	}
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for i := range indexes {
+				results[i] = task(i)
+				close(done[i])
+			}
+		}()
+	}
+
+	go func() {
+		for i := 0; i < n; i++ {
+			indexes <- i
+		}
+		close(indexes)
+	}()
+
+	for i := 0; i < n; i++ {
+		<-done[i]
+
+		emit(i, results[i])
+
+		var zero T
+		results[i] = zero
+	}
+
+	wg.Wait()
+}
+
+// KeyedMutex serializes work which shares the same key, e.g. mutants which are swapped into the same package.
+type KeyedMutex struct {
+	mu    sync.Mutex
+	locks map[string]*sync.Mutex
+}
+
+// NewKeyedMutex creates and returns a new initialized KeyedMutex.
+func NewKeyedMutex() *KeyedMutex {
+	return &KeyedMutex{locks: make(map[string]*sync.Mutex)}
+}
+
+// Lock locks the given key and returns the function which unlocks it again.
+func (k *KeyedMutex) Lock(key string) (unlock func()) {
+	k.mu.Lock()
+	l, ok := k.locks[key]
+	if !ok {
+		l = &sync.Mutex{}
+		k.locks[key] = l
+	}
+	k.mu.Unlock()
+
+	l.Lock()
+
+	return l.Unlock
+}
@@ -0,0 +1,97 @@
+package runner
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunEmitsInOrder(t *testing.T) {
+	for _, workers := range []int{1, 3, 16} {
+		var emitted []int
+
+		Run(workers, 20, func(i int) int {
+			// Make later tasks finish first
+			time.Sleep(time.Duration(20-i) * time.Millisecond)
+
+			return i * i
+		}, func(i int, result int) {
+			assert.Equal(t, i*i, result)
+
+			emitted = append(emitted, i)
+		})
+
+		expected := make([]int, 20)
+		for i := range expected {
+			expected[i] = i
+		}
+		assert.Equal(t, expected, emitted, "workers %d", workers)
+	}
+}
+
+func TestRunUsesWorkers(t *testing.T) {
+	var running, maxRunning int32
+
+	Run(4, 16, func(i int) struct{} {
+		n := atomic.AddInt32(&running, 1)
+		for {
+			m := atomic.LoadInt32(&maxRunning)
+			if n <= m || atomic.CompareAndSwapInt32(&maxRunning, m, n) {
+				break
+			}
+		}
+
+		time.Sleep(10 * time.Millisecond)
+		atomic.AddInt32(&running, -1)
+
+		return struct{}{}
+	}, func(int, struct{}) {})
+
+	assert.True(t, maxRunning > 1)
+	assert.True(t, maxRunning <= 4)
+}
+
+func TestRunWithoutTasks(t *testing.T) {
+	Run(4, 0, func(i int) int {
+		assert.Fail(t, "task must not be called")
+
+		return 0
+	}, func(int, int) {
+		assert.Fail(t, "emit must not be called")
+	})
+}
+
+func TestKeyedMutex(t *testing.T) {
+	k := NewKeyedMutex()
+
+	var wg sync.WaitGroup
+	var inside int32
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			unlock := k.Lock("pkg")
+			defer unlock()
+
+			assert.Equal(t, int32(1), atomic.AddInt32(&inside, 1))
+			time.Sleep(time.Millisecond)
+			atomic.AddInt32(&inside, -1)
+		}()
+	}
+	wg.Wait()
+
+	// Different keys do not block each other
+	unlock := k.Lock("a")
+	k.Lock("b")()
+	unlock()
+}
+
+func TestWorkers(t *testing.T) {
+	assert.Equal(t, 3, Workers(3))
+	assert.True(t, Workers(0) >= 1)
+}
@@ -0,0 +1,67 @@
+package runner
+
+import (
+	"errors"
+	"os/exec"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+// TimeoutExitCode is the exit code which denotes that a command was killed because it ran into its timeout.
+// It is the same exit code the timeout command of GNU coreutils uses.
+const TimeoutExitCode = 124
+
+// waitDelay bounds the time we wait for the output of a killed process group.
+const waitDelay = 5 * time.Second
+
+// Execute runs the command in its own process group and kills the whole group if it does not finish within the timeout.
+// A timeout of zero means that the command is never killed. The returned exit code is TimeoutExitCode if the command was killed.
+func Execute(cmd *exec.Cmd, timeout time.Duration) (exitCode int, err error) {
+	setProcessGroup(cmd)
+	cmd.WaitDelay = waitDelay
+
+	if err := cmd.Start(); err != nil {
+		return 0, err
+	}
+
+	var timedOut atomic.Bool
+	if timeout > 0 {
+		timer := time.AfterFunc(timeout, func() {
+			timedOut.Store(true)
+
+			killProcessGroup(cmd)
+		})
+		defer timer.Stop()
+	}
+
+	err = cmd.Wait()
+	if timedOut.Load() {
+		return TimeoutExitCode, nil
+	}
+
+	if err == nil {
+		return 0, nil
+	}
+
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		if status, ok := exitErr.Sys().(syscall.WaitStatus); ok {
+			return status.ExitStatus(), nil
+		}
+
+		return exitErr.ExitCode(), nil
+	}
+
+	return 0, err
+}
+
+// Timeout returns the timeout for a command whose run on the original code took baseline.
+func Timeout(baseline time.Duration, factor float64, minimum time.Duration) time.Duration {
+	timeout := time.Duration(float64(baseline) * factor)
+	if timeout < minimum {
+		return minimum
+	}
+
+	return timeout
+}
@@ -0,0 +1,42 @@
+package runner
+
+import (
+	"os/exec"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExecute(t *testing.T) {
+	exitCode, err := Execute(exec.Command("sh", "-c", "exit 0"), time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, 0, exitCode)
+
+	exitCode, err = Execute(exec.Command("sh", "-c", "exit 2"), 0)
+	require.NoError(t, err)
+	assert.Equal(t, 2, exitCode)
+
+	_, err = Execute(exec.Command("/does/not/exist"), 0)
+	assert.Error(t, err)
+}
+
+func TestExecuteKillsProcessGroup(t *testing.T) {
+	start := time.Now()
+
+	// The child keeps the output pipe open, so only killing the whole group ends the command in time
+	cmd := exec.Command("sh", "-c", "sleep 30 & sleep 30")
+	_, err := cmd.StdoutPipe()
+	require.NoError(t, err)
+
+	exitCode, err := Execute(cmd, 100*time.Millisecond)
+	require.NoError(t, err)
+	assert.Equal(t, TimeoutExitCode, exitCode)
+	assert.Less(t, time.Since(start), waitDelay)
+}
+
+func TestTimeout(t *testing.T) {
+	assert.Equal(t, 10*time.Second, Timeout(time.Second, 3, 10*time.Second))
+	assert.Equal(t, 30*time.Second, Timeout(10*time.Second, 3, 10*time.Second))
+}
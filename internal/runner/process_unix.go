@@ -0,0 +1,20 @@
+//go:build !windows
+
+package runner
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+func setProcessGroup(cmd *exec.Cmd) {
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.Setpgid = true
+}
+
+func killProcessGroup(cmd *exec.Cmd) {
+	// The negative PID addresses the whole process group, e.g. the test binary which is started by go test
+	_ = syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+}
@@ -0,0 +1,104 @@
+package sampling
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/avito-tech/go-mutesting/internal/models"
+)
+
+// ConfidenceLevel of the interval of the estimated mutation score
+const ConfidenceLevel = 0.95
+
+// z quantile of the standard normal distribution for ConfidenceLevel
+const z = 1.959963984540054
+
+// Size returns the count of mutations of a sample of the given population.
+// The sample is given as a fraction of the population (e.g. 0.1) or as a count (e.g. 200), a count larger than the population takes all of it.
+func Size(sample string, population int) (int, error) {
+	if !strings.Contains(sample, ".") {
+		count, err := strconv.Atoi(sample)
+		if err != nil || count < 1 {
+			return 0, fmt.Errorf("sample %q is neither a fraction between 0 and 1 nor a positive count", sample)
+		}
+
+		return min(count, population), nil
+	}
+
+	fraction, err := strconv.ParseFloat(sample, 64)
+	if err != nil || fraction <= 0 || fraction > 1 {
+		return 0, fmt.Errorf("sample %q is neither a fraction between 0 and 1 nor a positive count", sample)
+	}
+
+	return int(math.Ceil(fraction * float64(population))), nil
+}
+
+// Order returns the indexes of the given keys in a random order which is determined by the seed.
+// The place of a key only depends on the seed and the key itself, so keys keep their order relative to each other if other keys are added or removed.
+func Order(keys []string, seed int64) []int {
+	hashes := make([]uint64, len(keys))
+	for i, key := range keys {
+		h := fnv.New64a()
+		_, _ = fmt.Fprintf(h, "%d\n%s", seed, key)
+		hashes[i] = h.Sum64()
+	}
+
+	order := make([]int, len(keys))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return hashes[order[i]] < hashes[order[j]]
+	})
+
+	return order
+}
+
+// Interval returns the Wilson score interval at ConfidenceLevel of the share of detected mutations in a population, of which a sample was tested.
+// The sample is drawn without replacement, which is corrected for by the size of the population, so the interval is exact if the whole population was tested.
+func Interval(detected int64, tested int64, population int64) (float64, float64) {
+	if tested == 0 {
+		return 0, 1
+	}
+
+	p := float64(detected) / float64(tested)
+	if tested >= population {
+		return p, p
+	}
+
+	// Effective size of the sample under the finite population correction
+	n := float64(tested)
+	if population > 1 {
+		n *= float64(population-1) / float64(population-tested)
+	}
+
+	denominator := 1 + z*z/n
+	center := (p + z*z/(2*n)) / denominator
+	margin := z * math.Sqrt(p*(1-p)/n+z*z/(4*n*n)) / denominator
+
+	return math.Max(0, center-margin), math.Min(1, center+margin)
+}
+
+// Estimate sets the interval of the mutation score of all mutations of the sampling, which is estimated from its tested mutations which were not prioritized.
+func Estimate(s *models.Sampling) {
+	s.ConfidenceLevel = ConfidenceLevel
+
+	// Mutations which were not tested because the budget ran out are the last ones, so none of them is prioritized unless no other one was tested
+	picked := s.Sampled - s.Prioritized
+	tested := int64(max(0, picked-s.OverBudget))
+	var population int64
+	if s.Sampled > 0 {
+		// The prioritized mutations are assumed to be as common among all mutations as among the picked ones
+		population = int64(s.Population) * int64(picked) / int64(s.Sampled)
+	}
+	// Only the share of the tested mutations which counts for the score is known
+	if tested > 0 {
+		population = population * s.Counted / tested
+	}
+
+	s.MsiLow, s.MsiHigh = Interval(s.Detected, s.Counted, population)
+}
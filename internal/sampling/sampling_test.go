@@ -0,0 +1,88 @@
+package sampling
+
+import (
+	"fmt"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSize(t *testing.T) {
+	for sample, expected := range map[string]int{
+		"0.1":   10,
+		"0.15":  15,
+		"0.001": 1,
+		"1.0":   100,
+		"20":    20,
+		"200":   100,
+	} {
+		size, err := Size(sample, 100)
+		assert.NoError(t, err, sample)
+		assert.Equal(t, expected, size, sample)
+	}
+
+	for _, sample := range []string{"", "0", "0.0", "1.5", "-3", "a", "ten"} {
+		_, err := Size(sample, 100)
+		assert.Error(t, err, sample)
+	}
+}
+
+func TestOrder(t *testing.T) {
+	var keys []string
+	for i := 0; i < 100; i++ {
+		keys = append(keys, fmt.Sprintf("%032d", i))
+	}
+
+	order := Order(keys, 1)
+	assert.Equal(t, order, Order(keys, 1))
+	assert.NotEqual(t, order, Order(keys, 2))
+
+	sorted := append([]int(nil), order...)
+	sort.Ints(sorted)
+	for i, index := range sorted {
+		assert.Equal(t, i, index)
+	}
+
+	// Removing keys does not change the order of the others
+	var before []string
+	for _, index := range order {
+		before = append(before, keys[index])
+	}
+
+	var after []string
+	for _, index := range Order(keys[50:], 1) {
+		after = append(after, keys[50:][index])
+	}
+
+	var expected []string
+	for _, key := range before {
+		if key >= keys[50] {
+			expected = append(expected, key)
+		}
+	}
+	assert.Equal(t, expected, after)
+}
+
+func TestInterval(t *testing.T) {
+	low, high := Interval(50, 100, 10000)
+	assert.InDelta(t, 0.404, low, 0.001)
+	assert.InDelta(t, 0.596, high, 0.001)
+
+	// A larger share of the population is known more precisely
+	lowLarger, highLarger := Interval(50, 100, 200)
+	assert.Greater(t, lowLarger, low)
+	assert.Less(t, highLarger, high)
+
+	low, high = Interval(30, 40, 40)
+	assert.Equal(t, 0.75, low)
+	assert.Equal(t, 0.75, high)
+
+	low, high = Interval(0, 0, 40)
+	assert.Equal(t, 0.0, low)
+	assert.Equal(t, 1.0, high)
+
+	low, high = Interval(10, 10, 1000)
+	assert.Less(t, low, 1.0)
+	assert.Equal(t, 1.0, high)
+}
@@ -0,0 +1,345 @@
+package schemata
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/printer"
+	"go/token"
+	"sort"
+	"strings"
+)
+
+// ActiveEnv environment variable which selects the active mutation of an instrumented test binary
+const ActiveEnv = "MUTESTING_ACTIVE_MUTANT"
+
+// activeFunc is generated into every instrumented package and guards the alternatives of the mutations
+const activeFunc = "mutestingSchemataActive"
+
+// File collects the schemata of the mutations of one source file.
+// Every mutation replaces the statements of its innermost block with a guarded alternative, e.g.
+//
+//	if mutestingSchemataActive("a.go.17") {
+//		return a - b
+//	} else {
+//		return a + b
+//	}
+type File struct {
+	fset *token.FileSet
+	src  []byte
+
+	// blocks holds the byte span of the statements of every block which can carry schemata
+	blocks  map[ast.Node]span
+	parents map[ast.Node]ast.Node
+
+	mutants []mutant
+}
+
+// Region lines of an instrumented file which belong to the given mutations
+type Region struct {
+	StartLine int
+	EndLine   int
+	IDs       []string
+}
+
+type span struct {
+	start int
+	end   int
+}
+
+type mutant struct {
+	id    string
+	block span
+	// text holds the mutated statements of the block
+	text string
+}
+
+// NewFile prepares the schemata of the given file, src must be the source the file was parsed from.
+func NewFile(fset *token.FileSet, file *ast.File, src []byte) *File {
+	f := &File{
+		fset:    fset,
+		src:     src,
+		blocks:  make(map[ast.Node]span),
+		parents: make(map[ast.Node]ast.Node),
+	}
+
+	tokenFile := fset.File(file.Pos())
+	offset := func(pos token.Pos) int {
+		return tokenFile.Offset(pos)
+	}
+
+	// Labels are scoped to the whole function, so blocks which contain one cannot be duplicated
+	labeled := make(map[ast.Node]bool)
+
+	var stack []ast.Node
+	ast.Inspect(file, func(node ast.Node) bool {
+		if node == nil {
+			stack = stack[:len(stack)-1]
+
+			return true
+		}
+
+		if len(stack) > 0 {
+			f.parents[node] = stack[len(stack)-1]
+		}
+		stack = append(stack, node)
+
+		if _, ok := node.(*ast.LabeledStmt); ok {
+			for _, ancestor := range stack {
+				labeled[ancestor] = true
+			}
+		}
+
+		switch n := node.(type) {
+		case *ast.BlockStmt:
+			if n.Lbrace.IsValid() && n.Rbrace.IsValid() {
+				f.blocks[n] = span{start: offset(n.Lbrace) + 1, end: offset(n.Rbrace)}
+			}
+		case *ast.CaseClause:
+			f.blocks[n] = clauseSpan(offset, n.Colon, n.Body)
+		case *ast.CommClause:
+			f.blocks[n] = clauseSpan(offset, n.Colon, n.Body)
+		}
+
+		return true
+	})
+
+	for node := range labeled {
+		delete(f.blocks, node)
+	}
+
+	return f
+}
+
+// Add records the currently applied mutation of the given node with the given ID.
+// It returns false if the mutation cannot be expressed as a schema, e.g. because it changes a package level declaration.
+func (f *File) Add(id string, node ast.Node) bool {
+	for ; node != nil; node = f.parents[node] {
+		block, ok := f.blocks[node]
+		if !ok {
+			if isBlock(node) {
+				// Blocks which cannot carry schemata cannot be part of a larger one either
+				return false
+			}
+
+			continue
+		}
+
+		var stmts []ast.Stmt
+		switch n := node.(type) {
+		case *ast.BlockStmt:
+			stmts = n.List
+		case *ast.CaseClause:
+			stmts = n.Body
+		case *ast.CommClause:
+			stmts = n.Body
+		}
+
+		if len(stmts) > 0 {
+			if b, ok := stmts[len(stmts)-1].(*ast.BranchStmt); ok && b.Tok == token.FALLTHROUGH {
+				// fallthrough must stay the last statement of its case
+				return false
+			}
+		}
+
+		var text bytes.Buffer
+		for _, stmt := range stmts {
+			if err := printer.Fprint(&text, f.fset, stmt); err != nil {
+				return false
+			}
+			text.WriteString("\n")
+		}
+
+		f.mutants = append(f.mutants, mutant{
+			id:    id,
+			block: block,
+			text:  text.String(),
+		})
+
+		return true
+	}
+
+	return false
+}
+
+// Len returns the number of recorded mutations.
+func (f *File) Len() int {
+	return len(f.mutants)
+}
+
+// Render returns the source of the file with the schemata of all mutations which are not excluded.
+// The regions tell which lines of the source belong to which mutations.
+func (f *File) Render(excluded map[string]bool) ([]byte, []Region) {
+	bySpan := make(map[span][]mutant)
+	for _, m := range f.mutants {
+		if !excluded[m.id] {
+			bySpan[m.block] = append(bySpan[m.block], m)
+		}
+	}
+
+	spans := make([]span, 0, len(bySpan))
+	for s := range bySpan {
+		spans = append(spans, s)
+	}
+	sort.Slice(spans, func(i, j int) bool {
+		if spans[i].start != spans[j].start {
+			return spans[i].start < spans[j].start
+		}
+
+		return spans[i].end > spans[j].end
+	})
+
+	r := &renderer{
+		src:    f.src,
+		bySpan: bySpan,
+		spans:  spans,
+	}
+	r.render(0, len(f.src))
+
+	out := []byte(r.out.String())
+
+	// Offsets of the regions are turned into lines
+	lineStarts := []int{0}
+	for i, c := range out {
+		if c == '\n' {
+			lineStarts = append(lineStarts, i+1)
+		}
+	}
+	line := func(offset int) int {
+		return sort.Search(len(lineStarts), func(i int) bool {
+			return lineStarts[i] > offset
+		})
+	}
+
+	regions := make([]Region, 0, len(r.regions))
+	for _, region := range r.regions {
+		regions = append(regions, Region{
+			StartLine: line(region.start),
+			EndLine:   line(region.end - 1),
+			IDs:       region.ids,
+		})
+	}
+
+	return out, regions
+}
+
+// Locate returns the mutations of the innermost region which contains the given line.
+func Locate(regions []Region, line int) []string {
+	var found *Region
+
+	for i := range regions {
+		region := &regions[i]
+		if line < region.StartLine || line > region.EndLine {
+			continue
+		}
+
+		if found == nil || region.EndLine-region.StartLine < found.EndLine-found.StartLine {
+			found = region
+		}
+	}
+
+	if found == nil {
+		return nil
+	}
+
+	return found.IDs
+}
+
+// HelperSource returns the source of the file which is added to every instrumented package.
+func HelperSource(pkgName string) []byte {
+	return []byte(fmt.Sprintf(`// Code generated by go-mutesting. DO NOT EDIT.
+
+package %s
+
+import "os"
+
+var mutestingSchemataMutant = os.Getenv(%q)
+
+func %s(id string) bool {
+	return mutestingSchemataMutant == id
+}
+`, pkgName, ActiveEnv, activeFunc))
+}
+
+type renderer struct {
+	src     []byte
+	bySpan  map[span][]mutant
+	spans   []span
+	next    int
+	out     strings.Builder
+	regions []struct {
+		start int
+		end   int
+		ids   []string
+	}
+}
+
+// render writes the source between the given offsets, spans with mutations are replaced by their schemata.
+func (r *renderer) render(start int, end int) {
+	pos := start
+
+	for r.next < len(r.spans) && r.spans[r.next].start < end {
+		s := r.spans[r.next]
+		r.next++
+
+		r.out.Write(r.src[pos:s.start])
+		r.renderSpan(s)
+		pos = s.end
+	}
+
+	r.out.Write(r.src[pos:end])
+}
+
+func (r *renderer) renderSpan(s span) {
+	mutants := r.bySpan[s]
+
+	chainStart := r.out.Len()
+	ids := make([]string, 0, len(mutants))
+
+	r.out.WriteString("\n")
+	for i, m := range mutants {
+		branchStart := r.out.Len()
+
+		if i > 0 {
+			r.out.WriteString("} else ")
+		}
+		fmt.Fprintf(&r.out, "if %s(%q) {\n", activeFunc, m.id)
+		r.out.WriteString(m.text)
+
+		r.addRegion(branchStart, r.out.Len(), []string{m.id})
+		ids = append(ids, m.id)
+	}
+
+	r.out.WriteString("} else {\n")
+	r.render(s.start, s.end)
+	// The closing brace of the block follows on the same line, so errors like a missing return are part of the chain
+	r.out.WriteString("\n}")
+
+	r.addRegion(chainStart, r.out.Len(), ids)
+}
+
+func (r *renderer) addRegion(start int, end int, ids []string) {
+	r.regions = append(r.regions, struct {
+		start int
+		end   int
+		ids   []string
+	}{start: start, end: end, ids: ids})
+}
+
+func isBlock(node ast.Node) bool {
+	switch node.(type) {
+	case *ast.BlockStmt, *ast.CaseClause, *ast.CommClause:
+		return true
+	}
+
+	return false
+}
+
+func clauseSpan(offset func(token.Pos) int, colon token.Pos, body []ast.Stmt) span {
+	start := offset(colon) + 1
+	if len(body) == 0 {
+		return span{start: start, end: start}
+	}
+
+	return span{start: start, end: offset(body[len(body)-1].End())}
+}
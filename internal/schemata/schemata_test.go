@@ -0,0 +1,168 @@
+package schemata
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const source = `package example
+
+var limit = 10
+
+func Add(a, b int) int {
+	if a > limit {
+		return a
+	}
+
+	return a + b
+}
+
+func Loop(n int) {
+outer:
+	for i := 0; i < n; i++ {
+		if i > 2 {
+			break outer
+		}
+	}
+}
+`
+
+func parse(t *testing.T) (*token.FileSet, *ast.File) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "example.go", source, parser.ParseComments)
+	require.NoError(t, err)
+
+	return fset, file
+}
+
+// find returns the first node of the given file which matches.
+func find[T ast.Node](file *ast.File, match func(T) bool) T {
+	var found T
+	var ok bool
+
+	ast.Inspect(file, func(node ast.Node) bool {
+		if n, is := node.(T); is && !ok && match(n) {
+			found, ok = n, true
+		}
+
+		return !ok
+	})
+
+	return found
+}
+
+func TestRender(t *testing.T) {
+	fset, file := parse(t)
+	f := NewFile(fset, file, []byte(source))
+
+	sum := find(file, func(n *ast.BinaryExpr) bool { return n.Op == token.ADD })
+	sum.Op = token.SUB
+	assert.True(t, f.Add("example.go.0", sum))
+	sum.Op = token.ADD
+
+	cond := find(file, func(n *ast.BinaryExpr) bool { return n.Op == token.GTR })
+	cond.Op = token.GEQ
+	assert.True(t, f.Add("example.go.1", cond))
+	cond.Op = token.GTR
+
+	ret := find(file, func(n *ast.ReturnStmt) bool { return len(n.Results) == 1 && n.Results[0].(*ast.Ident).Name == "a" })
+	ret.Results[0] = ast.NewIdent("b")
+	assert.True(t, f.Add("example.go.2", ret))
+
+	assert.Equal(t, 3, f.Len())
+
+	rendered, regions := f.Render(nil)
+
+	assert.Equal(t, `package example
+
+var limit = 10
+
+func Add(a, b int) int {
+if mutestingSchemataActive("example.go.0") {
+if a > limit {
+	return a
+}
+return a - b
+} else if mutestingSchemataActive("example.go.1") {
+if a >= limit {
+	return a
+}
+return a + b
+} else {
+
+	if a > limit {
+if mutestingSchemataActive("example.go.2") {
+return b
+} else {
+
+		return a
+	
+}}
+
+	return a + b
+
+}}
+
+func Loop(n int) {
+outer:
+	for i := 0; i < n; i++ {
+		if i > 2 {
+			break outer
+		}
+	}
+}
+`, string(rendered))
+
+	_, err := parser.ParseFile(token.NewFileSet(), "example.go", rendered, 0)
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{"example.go.0"}, Locate(regions, 10))
+	assert.Equal(t, []string{"example.go.1"}, Locate(regions, 12))
+	assert.Equal(t, []string{"example.go.2"}, Locate(regions, 19))
+	assert.Equal(t, []string{"example.go.2"}, Locate(regions, 24))
+	assert.Equal(t, []string{"example.go.0", "example.go.1"}, Locate(regions, 28))
+	assert.Nil(t, Locate(regions, 4))
+}
+
+func TestRenderExcluded(t *testing.T) {
+	fset, file := parse(t)
+	f := NewFile(fset, file, []byte(source))
+
+	sum := find(file, func(n *ast.BinaryExpr) bool { return n.Op == token.ADD })
+	sum.Op = token.SUB
+	assert.True(t, f.Add("example.go.0", sum))
+	sum.Op = token.ADD
+
+	rendered, regions := f.Render(map[string]bool{"example.go.0": true})
+
+	assert.Equal(t, source, string(rendered))
+	assert.Empty(t, regions)
+}
+
+func TestAddUnsupported(t *testing.T) {
+	fset, file := parse(t)
+	f := NewFile(fset, file, []byte(source))
+
+	// Package level declarations have no block
+	limit := find(file, func(n *ast.BasicLit) bool { return n.Value == "10" })
+	assert.False(t, f.Add("example.go.0", limit))
+
+	// Labels cannot be duplicated
+	loop := find(file, func(n *ast.BinaryExpr) bool { return n.Op == token.LSS })
+	assert.False(t, f.Add("example.go.1", loop))
+
+	assert.Equal(t, 0, f.Len())
+}
+
+func TestHelperSource(t *testing.T) {
+	helper, err := parser.ParseFile(token.NewFileSet(), "helper.go", HelperSource("example"), 0)
+	require.NoError(t, err)
+
+	assert.Equal(t, "example", helper.Name.Name)
+	assert.Contains(t, string(HelperSource("example")), ActiveEnv)
+}
@@ -0,0 +1,5 @@
+package failing
+
+func add(a, b int) int {
+	return a + b
+}
@@ -0,0 +1,12 @@
+package failing
+
+import (
+	"testing"
+)
+
+// TestAdd fails on the original code on purpose
+func TestAdd(t *testing.T) {
+	if add(1, 2) != 4 {
+		t.Fail()
+	}
+}
@@ -0,0 +1,5 @@
+module example.com/consumer
+
+go 1.21
+
+require example.com/scope v0.0.0
@@ -0,0 +1,14 @@
+//go:build broken
+
+package quota
+
+import (
+	"testing"
+)
+
+// TestRemainingBroken fails on the original library, so the consumer must not be used with the stage which runs it
+func TestRemainingBroken(t *testing.T) {
+	if Remaining(10, 20) != -10 {
+		t.Fail()
+	}
+}
@@ -0,0 +1,10 @@
+package quota
+
+import (
+	"example.com/scope/lib"
+)
+
+// Remaining returns the remaining quota, which is never negative
+func Remaining(quota int, used int) int {
+	return lib.Max(quota-used, 0)
+}
@@ -0,0 +1,11 @@
+package quota
+
+import (
+	"testing"
+)
+
+func TestRemaining(t *testing.T) {
+	if Remaining(10, 4) != 6 || Remaining(10, 20) != 0 {
+		t.Fail()
+	}
+}
@@ -0,0 +1,3 @@
+module example.com/killmatrix
+
+go 1.21
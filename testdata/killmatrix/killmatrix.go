@@ -0,0 +1,11 @@
+package killmatrix
+
+// Add returns the sum of both numbers
+func Add(a, b int) int {
+	return a + b
+}
+
+// Sub returns the difference of both numbers
+func Sub(a, b int) int {
+	return a - b
+}
@@ -0,0 +1,24 @@
+package killmatrix
+
+import (
+	"testing"
+)
+
+func TestAdd(t *testing.T) {
+	if Add(1, 2) != 3 {
+		t.Fail()
+	}
+}
+
+// TestAddZero kills the mutants of Add as well, so it is redundant
+func TestAddZero(t *testing.T) {
+	if Add(0, 2) != 2 {
+		t.Fail()
+	}
+}
+
+func TestSub(t *testing.T) {
+	if Sub(3, 1) != 2 {
+		t.Fail()
+	}
+}
@@ -0,0 +1,10 @@
+package lib
+
+// Max returns the larger of both numbers
+func Max(a, b int) int {
+	if a > b {
+		return a
+	}
+
+	return b
+}
@@ -0,0 +1,12 @@
+package lib
+
+import (
+	"testing"
+)
+
+// TestMax leaves the real testing to the packages which use Max
+func TestMax(t *testing.T) {
+	if Max(1, 1) != 1 {
+		t.Fail()
+	}
+}
@@ -0,0 +1,10 @@
+package service
+
+import (
+	"example.com/scope/lib"
+)
+
+// Limit returns the limit of a request, which is at least the minimum
+func Limit(requested int, minimum int) int {
+	return lib.Max(requested, minimum)
+}
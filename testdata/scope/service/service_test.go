@@ -0,0 +1,11 @@
+package service
+
+import (
+	"testing"
+)
+
+func TestLimit(t *testing.T) {
+	if Limit(5, 10) != 10 || Limit(20, 10) != 20 {
+		t.Fail()
+	}
+}
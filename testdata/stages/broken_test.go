@@ -0,0 +1,14 @@
+//go:build broken
+
+package stages
+
+import (
+	"testing"
+)
+
+// TestAbsBroken fails on the original code, so the stage which runs it must not kill any mutation
+func TestAbsBroken(t *testing.T) {
+	if abs(-1) != -1 {
+		t.Fail()
+	}
+}
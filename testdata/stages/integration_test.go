@@ -0,0 +1,13 @@
+//go:build integration
+
+package stages
+
+import (
+	"testing"
+)
+
+func TestAbsNegative(t *testing.T) {
+	if abs(-1) != 1 {
+		t.Fail()
+	}
+}
@@ -0,0 +1,9 @@
+package stages
+
+func abs(a int) int {
+	if a < 0 {
+		return -a
+	}
+
+	return a
+}
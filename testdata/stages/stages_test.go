@@ -0,0 +1,12 @@
+package stages
+
+import (
+	"testing"
+)
+
+// TestAbs does not test negative numbers, which are left to the integration tests
+func TestAbs(t *testing.T) {
+	if abs(2) != 2 {
+		t.Fail()
+	}
+}
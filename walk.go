@@ -45,6 +45,14 @@ func (w *countWalk) Visit(node ast.Node) ast.Visitor {
 // MutateWalk mutates the given node with the given mutator returning a channel to control the mutation steps.
 // It traverses the AST of the given node and calls the method Check of the given mutator to verify that a node can be mutated by the mutator. If a node can be mutated the method Mutate of the given mutator is executed with the node and the control channel. After completion of the traversal the control channel is closed.
 func MutateWalk(pkg *types.Package, info *types.Info, node ast.Node, m mutator.Mutator) chan bool {
+	changed, _ := MutateWalkNodes(pkg, info, node, m)
+
+	return changed
+}
+
+// MutateWalkNodes mutates the given node like MutateWalk.
+// Additionally the returned function returns the node which is passed to the mutator of the mutation that is currently applied. It must only be called between receiving a mutation step and answering it.
+func MutateWalkNodes(pkg *types.Package, info *types.Info, node ast.Node, m mutator.Mutator) (chan bool, func() ast.Node) {
 	w := &mutateWalk{
 		changed: make(chan bool),
 		mutator: m,
@@ -58,7 +66,9 @@ func MutateWalk(pkg *types.Package, info *types.Info, node ast.Node, m mutator.M
 		close(w.changed)
 	}()
 
-	return w.changed
+	return w.changed, func() ast.Node {
+		return w.node
+	}
 }
 
 type mutateWalk struct {
@@ -66,6 +76,7 @@ type mutateWalk struct {
 	mutator mutator.Mutator
 	pkg     *types.Package
 	info    *types.Info
+	node    ast.Node
 }
 
 // Visit implements the Visit method of the ast.Visitor interface
@@ -75,6 +86,7 @@ func (w *mutateWalk) Visit(node ast.Node) ast.Visitor {
 	}
 
 	for _, m := range w.mutator(w.pkg, w.info, node) {
+		w.node = node
 		m.Change()
 		w.changed <- true
 		<-w.changed